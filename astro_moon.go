@@ -1,11 +1,10 @@
 package suriya
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/soh335/ical"
 	"log"
-	"path/filepath"
+	"math"
 	s "strings"
 	"time"
 )
@@ -42,65 +41,71 @@ func (m AstroMoon) IcalEvent() ical.VEvent {
 	return icalEvent(m)
 }
 
-func GetAstroMoons(fromDate time.Time, toDate time.Time) (moons []AstroMoon) {
-	var err error
-
-	for year := fromDate.Year(); year <= toDate.Year(); year++ {
-
-		// filenames are astro-YYYY.json
-
-		filename := fmt.Sprintf("astro-%d.json", year)
-		filepath := "/" + filepath.Join(AstroMoonDir, filename)
-
-		var data []byte
-
-		if data, err = FSByte(useLocal, filepath); err != nil {
-			if verbose {
-				log.Printf("%v, %s\n", err, filepath)
-			}
-			return moons
-		}
-
-		var resp AerisResp
-		if err := json.Unmarshal(data, &resp); err != nil {
-			panic(err)
-			return moons
-		}
-
-		if resp.Success != true {
-			if verbose {
-				log.Printf("%s was not successful", filepath)
-			}
-			continue
-		}
-
-		if len(resp.Error.Code) != 0 {
-			if verbose {
-				log.Println("%s has error: %s", filepath, resp.Error.Description)
-			}
-			continue
-		}
-
-		for _, aemoon := range resp.Response {
-			var m AstroMoon
-			m.Date = aemoon.DateTimeISO.UTC()
-			if m.Date.Before(fromDate) || m.Date.After(toDate) {
-				continue
-			}
-			m.Phase = phaseCodes[aemoon.Code]
-
-			// Not filtering the phase. First- and Last Quarter is
-			// used in the year planner PDF. If need to regenerate
-			// JSON for splendidmoons.github.io, filter the return
-			// value instead here.
-			//
-			// if m.Phase == "full" || m.Phase == "new" {
-			// 	moons = append(moons, m)
-			// }
-
-			moons = append(moons, m)
-		}
+// The astronomical moons between the dates, from AstroMoonSource.
+func GetAstroMoons(fromDate time.Time, toDate time.Time) []AstroMoon {
+	return GetAstroMoonsFrom(astroMoonSource(), fromDate, toDate)
+}
+
+// AstroMoonSource, after the data files with UseAstroMoonData
+func astroMoonSource() AstroMoonProvider {
+	if UseAstroMoonData {
+		return ChainProvider{AerisProvider{}, AstroMoonSource}
+	}
+	return AstroMoonSource
+}
+
+/*
+The astronomical moons in the local time of the location, so that the date of
+a moon is the civil date where it is observed. A New Moon at 20:00 UTC is on
+the next day in Bangkok.
+
+The dates of the range are taken as the wall clock dates in the location, as
+the dates of the calendar are.
+*/
+func GetAstroMoonsIn(fromDate time.Time, toDate time.Time, loc *time.Location) []AstroMoon {
+	moons := GetAstroMoonsFrom(astroMoonSource(), inLocation(fromDate, loc), inLocation(toDate, loc))
+	for i := range moons {
+		moons[i].Date = moons[i].Date.In(loc)
+	}
+	return moons
+}
+
+// The same wall clock time in the location
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+/*
+The local mean time of the longitude in degrees, east being positive. For
+places where the time zone is not known, or to follow the Sun instead of the
+civil time.
+*/
+func LongitudeLocation(longitude float64) *time.Location {
+	offset := int(math.Floor(longitude/15*3600 + 0.5))
+
+	sign := "+"
+	abs := offset
+	if offset < 0 {
+		sign = "-"
+		abs = -offset
+	}
+	name := fmt.Sprintf("LMT%s%02d:%02d", sign, abs/3600, abs%3600/60)
+
+	return time.FixedZone(name, offset)
+}
+
+// The astronomical moons between the dates from the provider. The errors are
+// only logged, the moons are what the provider could give.
+func GetAstroMoonsFrom(provider AstroMoonProvider, fromDate time.Time, toDate time.Time) []AstroMoon {
+	moons, err := provider.AstroMoons(fromDate, toDate)
+	if err != nil && verbose {
+		log.Printf("%v\n", err)
 	}
 
+	// Not filtering the phase. First- and Last Quarter is
+	// used in the year planner PDF. If need to regenerate
+	// JSON for splendidmoons.github.io, filter the return
+	// value for "full" and "new".
+
 	return moons
 }
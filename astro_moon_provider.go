@@ -0,0 +1,397 @@
+package suriya
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	s "strings"
+	"time"
+)
+
+// A source of the astronomical moons, such as the data files, a table from an
+// observatory, or the calculation.
+type AstroMoonProvider interface {
+	AstroMoons(fromDate time.Time, toDate time.Time) ([]AstroMoon, error)
+}
+
+// The provider of GetAstroMoons(). To prefer the data files and calculate the
+// rest:
+//
+//	suriya.AstroMoonSource = suriya.ChainProvider{suriya.AerisProvider{}, suriya.CalculatedProvider{}}
+var AstroMoonSource AstroMoonProvider = CalculatedProvider{}
+
+// The moons calculated with CalculateAstroMoons().
+type CalculatedProvider struct{}
+
+func (p CalculatedProvider) AstroMoons(fromDate time.Time, toDate time.Time) ([]AstroMoon, error) {
+	return CalculateAstroMoons(fromDate, toDate), nil
+}
+
+// The Aeris API JSON files, astro-YYYY.json. From the embedded AstroMoonDir
+// when Dir is empty, otherwise from the files in Dir.
+type AerisProvider struct {
+	Dir string
+}
+
+func (p AerisProvider) AstroMoons(fromDate time.Time, toDate time.Time) (moons []AstroMoon, err error) {
+	for year := fromDate.Year(); year <= toDate.Year(); year++ {
+		filename := fmt.Sprintf("astro-%d.json", year)
+
+		var data []byte
+		if len(p.Dir) == 0 {
+			filename = "/" + filepath.Join(AstroMoonDir, filename)
+			data, err = FSByte(useLocal, filename)
+		} else {
+			filename = filepath.Join(p.Dir, filename)
+			data, err = ioutil.ReadFile(filename)
+		}
+		if err != nil {
+			return moons, fmt.Errorf("%v, %s", err, filename)
+		}
+
+		year_moons, err := ParseAerisJSON(data)
+		if err != nil {
+			return moons, fmt.Errorf("%v, %s", err, filename)
+		}
+
+		moons = append(moons, filterAstroMoons(year_moons, fromDate, toDate)...)
+	}
+
+	return moons, nil
+}
+
+// The moons of an Aeris API moonphases response.
+func ParseAerisJSON(data []byte) (moons []AstroMoon, err error) {
+	var resp AerisResp
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return moons, err
+	}
+
+	if resp.Success != true {
+		return moons, fmt.Errorf("The response was not successful")
+	}
+
+	if len(resp.Error.Code) != 0 {
+		return moons, fmt.Errorf("The response has error: %s", resp.Error.Description)
+	}
+
+	for _, aemoon := range resp.Response {
+		if aemoon.Code < 0 || aemoon.Code >= len(phaseCodes) {
+			return moons, fmt.Errorf("Unknown phase code: %d", aemoon.Code)
+		}
+		moons = append(moons, AstroMoon{
+			Phase: phaseCodes[aemoon.Code],
+			Date:  aemoon.DateTimeISO.UTC(),
+		})
+	}
+
+	return moons, nil
+}
+
+// Moons read once into memory, such as from ParseAstroMoonCSV() or
+// ParseUSNOTable(). Sorted by date.
+type TableProvider []AstroMoon
+
+func (p TableProvider) AstroMoons(fromDate time.Time, toDate time.Time) ([]AstroMoon, error) {
+	return filterAstroMoons(p, fromDate, toDate), nil
+}
+
+/*
+Providers in the order of preference. The moons of the first provider are
+taken, and the later providers fill in the moons it doesn't have, so a table
+of a few months or the data files of some years can be completed by the
+calculation.
+
+A moon is the same as one of an earlier provider when it has the same phase
+within a week. An error is returned only when no provider has any moons in a
+year of the range.
+*/
+type ChainProvider []AstroMoonProvider
+
+func (p ChainProvider) AstroMoons(fromDate time.Time, toDate time.Time) (moons []AstroMoon, err error) {
+	for year := fromDate.Year(); year <= toDate.Year(); year++ {
+		from := maxTime(fromDate, time.Date(year, 1, 1, 0, 0, 0, 0, fromDate.Location()))
+		to := minTime(toDate, time.Date(year+1, 1, 1, 0, 0, 0, 0, toDate.Location()).Add(-time.Second))
+
+		var year_moons []AstroMoon
+		var year_err error
+
+		for _, provider := range p {
+			provider_moons, provider_err := provider.AstroMoons(from, to)
+			if provider_err != nil {
+				year_err = provider_err
+			}
+			for _, m := range provider_moons {
+				if !hasAstroMoon(year_moons, m) {
+					year_moons = append(year_moons, m)
+				}
+			}
+		}
+
+		if len(year_moons) == 0 && year_err != nil {
+			err = year_err
+			continue
+		}
+
+		sort.Sort(astroMoonsByDate(year_moons))
+		moons = append(moons, year_moons...)
+	}
+
+	return moons, err
+}
+
+// Whether the moons have the phase of the moon within a week
+func hasAstroMoon(moons []AstroMoon, moon AstroMoon) bool {
+	for _, m := range moons {
+		d := m.Date.Sub(moon.Date)
+		if m.Phase == moon.Phase && d < 7*24*time.Hour && d > -7*24*time.Hour {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Moons from CSV with a date and a phase column. When the first row is a header
+with the columns "date" (or "time") and "phase", the columns are found by
+name, otherwise the first is the date and the second is the phase.
+
+The date is RFC 3339, or "2006-01-02 15:04:05", "2006-01-02 15:04" or
+"2006-01-02" in UTC. The phase is new, first quarter, full, last quarter (or
+waxing and waning as in phaseCodes), or the code 0-3.
+*/
+func ParseAstroMoonCSV(r io.Reader) (TableProvider, error) {
+	var moons TableProvider
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return moons, err
+	}
+
+	date_col, phase_col := 0, 1
+
+	for n, record := range records {
+		if n == 0 {
+			if d, p := csvHeaderColumns(record); d >= 0 && p >= 0 {
+				date_col, phase_col = d, p
+				continue
+			}
+		}
+
+		if len(record) <= date_col || len(record) <= phase_col {
+			return moons, fmt.Errorf("Missing columns on line %d", n+1)
+		}
+
+		date, err := parseAstroMoonTime(record[date_col])
+		if err != nil {
+			return moons, fmt.Errorf("Invalid date on line %d: %s", n+1, record[date_col])
+		}
+
+		phase, err := parsePhase(record[phase_col])
+		if err != nil {
+			return moons, fmt.Errorf("%v on line %d", err, n+1)
+		}
+
+		moons = append(moons, AstroMoon{Phase: phase, Date: date})
+	}
+
+	sort.Sort(astroMoonsByDate(moons))
+
+	return moons, nil
+}
+
+func csvHeaderColumns(record []string) (date_col int, phase_col int) {
+	date_col, phase_col = -1, -1
+	for i, col := range record {
+		switch s.ToLower(s.TrimSpace(col)) {
+		case "date", "time", "datetime":
+			date_col = i
+		case "phase":
+			phase_col = i
+		}
+	}
+	return date_col, phase_col
+}
+
+var astroMoonTimeFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+func parseAstroMoonTime(str string) (time.Time, error) {
+	str = s.TrimSpace(str)
+	for _, format := range astroMoonTimeFormats {
+		if t, err := time.Parse(format, str); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("Invalid date: %s", str)
+}
+
+var phaseNames = map[string]string{
+	"new":           "new",
+	"new moon":      "new",
+	"first quarter": "waxing",
+	"waxing":        "waxing",
+	"full":          "full",
+	"full moon":     "full",
+	"last quarter":  "waning",
+	"third quarter": "waning",
+	"waning":        "waning",
+}
+
+// The phase as in phaseCodes, from a name or the code 0-3
+func parsePhase(str string) (string, error) {
+	str = s.ToLower(s.Join(s.Fields(str), " "))
+
+	if phase, ok := phaseNames[str]; ok {
+		return phase, nil
+	}
+
+	if code, err := strconv.Atoi(str); err == nil && code >= 0 && code < len(phaseCodes) {
+		return phaseCodes[code], nil
+	}
+
+	return "", fmt.Errorf("Unknown phase: %s", str)
+}
+
+var usnoEntryRe = regexp.MustCompile(`([A-Z][a-z]{2})\s+(\d{1,2})\s+(\d{1,2})[\s:]+(\d{2})`)
+var usnoYearRe = regexp.MustCompile(`^\s*(-?\d{4})\b`)
+
+/*
+Moons from a USNO style "Phases of the Moon" table in Universal Time, with the
+columns New Moon, First Quarter, Full Moon and Last Quarter, such as:
+
+	        New Moon       First Quarter       Full Moon       Last Quarter
+
+	        d  h  m         d  h  m         d  h  m         d  h  m
+	2016                                                  Jan  2 05 30
+	      Jan 10 01 30    Jan 16 23 26    Jan 24 01 46    Feb  1 03 28
+
+The phase of an entry is the column heading nearest to it. The year is a four
+digit number at the start of a line, and it is advanced when the months wrap
+around from December to January.
+*/
+func ParseUSNOTable(r io.Reader) (TableProvider, error) {
+	var moons TableProvider
+
+	headings := []string{"New Moon", "First Quarter", "Full Moon", "Last Quarter"}
+	var columns []int
+
+	year := 0
+	last_month := time.Month(0)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if columns == nil && s.Contains(line, headings[0]) {
+			for _, h := range headings {
+				i := s.Index(line, h)
+				if i < 0 {
+					return moons, fmt.Errorf("Missing column: %s", h)
+				}
+				columns = append(columns, i+len(h)/2)
+			}
+			continue
+		}
+
+		if m := usnoYearRe.FindStringSubmatch(line); m != nil {
+			year, _ = strconv.Atoi(m[1])
+			last_month = 0
+		}
+
+		for _, idx := range usnoEntryRe.FindAllStringSubmatchIndex(line, -1) {
+			if columns == nil {
+				return moons, fmt.Errorf("Missing the column headings")
+			}
+			if year == 0 {
+				return moons, fmt.Errorf("Missing the year")
+			}
+
+			date, err := time.Parse("Jan 2 15 04", fmt.Sprintf("%s %s %s %s",
+				line[idx[2]:idx[3]], line[idx[4]:idx[5]], line[idx[6]:idx[7]], line[idx[8]:idx[9]]))
+			if err != nil {
+				return moons, fmt.Errorf("Invalid entry: %s", line[idx[0]:idx[1]])
+			}
+
+			if date.Month() < last_month {
+				year++
+			}
+			last_month = date.Month()
+
+			// the nearest column from the middle of the entry
+			mid := (idx[0] + idx[1]) / 2
+			phase := 0
+			for i, col := range columns {
+				if absInt(mid-col) < absInt(mid-columns[phase]) {
+					phase = i
+				}
+			}
+
+			moons = append(moons, AstroMoon{
+				Phase: phaseCodes[phase],
+				Date:  time.Date(year, date.Month(), date.Day(), date.Hour(), date.Minute(), 0, 0, time.UTC),
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return moons, err
+	}
+
+	sort.Sort(astroMoonsByDate(moons))
+
+	return moons, nil
+}
+
+type astroMoonsByDate []AstroMoon
+
+func (a astroMoonsByDate) Len() int           { return len(a) }
+func (a astroMoonsByDate) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a astroMoonsByDate) Less(i, j int) bool { return a[i].Date.Before(a[j].Date) }
+
+func filterAstroMoons(moons []AstroMoon, fromDate time.Time, toDate time.Time) (res []AstroMoon) {
+	for _, m := range moons {
+		if m.Date.Before(fromDate) || m.Date.After(toDate) {
+			continue
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
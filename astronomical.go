@@ -0,0 +1,103 @@
+package suriya
+
+import (
+	"fmt"
+	"time"
+)
+
+/*
+Some monasteries outside Thailand observe the uposatha on the day of the
+astronomical Full Moon and New Moon in their own time zone, instead of the day
+of the Suriyayatra.
+
+The months, seasons, adhikamāsa years and major events are kept as in the
+Mahānikāya calendar. Each uposatha is moved to the civil date of the nearest
+astronomical moon of the same phase, which is usually the same day or one day
+apart. DayOffset is the difference in days from the Mahānikāya uposatha, and the
+differing days are noted in the Comments.
+*/
+
+// The uposathas of the solar year on the astronomical moons in the location,
+// as calendarUposathas() for the astronomical calendar.
+func AstronomicalUposathas(solar_year int, loc *time.Location) []UposathaMoon {
+	var uposathas []UposathaMoon
+
+	mahanikaya := calendarUposathas(solar_year, CalendarToInt("mahanikaya"), loc)
+	if len(mahanikaya) == 0 {
+		return uposathas
+	}
+
+	// From a month before the first to a month after the last, to have the
+	// neighbours of each moon for the day counts.
+	from := mahanikaya[0].Date.AddDate(0, 0, -45)
+	to := mahanikaya[len(mahanikaya)-1].Date.AddDate(0, 0, 45)
+
+	var moons []AstroMoon
+	for _, m := range GetAstroMoonsIn(dayOf(from), dayOf(to), loc) {
+		if m.Phase == "new" || m.Phase == "full" {
+			moons = append(moons, m)
+		}
+	}
+
+	for _, mu := range mahanikaya {
+		uposathas = append(uposathas, mu.astronomicalUposatha(moons))
+	}
+
+	return uposathas
+}
+
+// The uposatha moved to the nearest astronomical moon of its phase. The moons
+// are the new and full moons in order.
+func (m UposathaMoon) astronomicalUposatha(moons []AstroMoon) UposathaMoon {
+	au := m
+	au.Calendar = CalendarToInt("astronomical")
+	au.HasAdhikavara = false
+
+	i := nearestAstroMoon(moons, m.Date, m.Phase)
+	if i < 0 {
+		au.Comments = "No astronomical moon"
+		return au
+	}
+
+	au.DayOffset = daysBetween(m.Date, moons[i].Date)
+	au.Date = m.Date.AddDate(0, 0, au.DayOffset)
+
+	if i > 0 {
+		au.U_Days = daysBetween(moons[i-1].Date, moons[i].Date)
+	}
+
+	// The month is from Full Moon to Full Moon, as in NextUposatha.
+	if m.Phase == "new" && i > 0 && i+1 < len(moons) {
+		au.M_Days = daysBetween(moons[i-1].Date, moons[i+1].Date)
+	} else if m.Phase == "full" && i > 1 {
+		au.M_Days = daysBetween(moons[i-2].Date, moons[i].Date)
+	}
+
+	if au.DayOffset != 0 {
+		au.Comments = fmt.Sprintf("Mahānikāya: %s", m.Date.Format("2006-01-02"))
+	}
+
+	return au
+}
+
+// Index of the moon of the phase nearest to the date, or -1.
+func nearestAstroMoon(moons []AstroMoon, date time.Time, phase string) int {
+	best := -1
+	best_days := 0
+	for i, am := range moons {
+		if am.Phase != phase {
+			continue
+		}
+		days := absInt(daysBetween(date, am.Date))
+		if best == -1 || days < best_days {
+			best = i
+			best_days = days
+		}
+	}
+	return best
+}
+
+// Days from the civil date of a to the civil date of b, each in its location.
+func daysBetween(a time.Time, b time.Time) int {
+	return int(dayOf(b).Sub(dayOf(a)).Hours() / 24)
+}
@@ -2,6 +2,7 @@ package suriya
 
 import (
 	"errors"
+	"log"
 	"sort"
 	s "strings"
 	"time"
@@ -17,6 +18,7 @@ type CalDay struct {
 	AstroMoon    AstroMoonSliceSingle    `json:",omitempty"`
 	MajorEvents  []MajorEvent            `json:",omitempty"`
 	Events       []Event                 `json:",omitempty"`
+	Eclipses     []Eclipse               `json:",omitempty"`
 }
 
 type UposathaMoonSliceSingle []UposathaMoon
@@ -92,15 +94,21 @@ func (s CalDaySlice) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
 
+// By the civil date in the location of each day, so that an astronomical moon
+// in local time is sorted with the uposatha of the same date.
 func (s CalDaySlice) Less(i, j int) bool {
+	a, b := dayOf(s[i].Date), dayOf(s[j].Date)
+	if !a.Equal(b) {
+		return a.Before(b)
+	}
 	return s[j].Date.After(s[i].Date)
 }
 
+// The day of the same civil date, the date being in its own location.
 func findCalDay(cal_days []CalDay, date time.Time) (*CalDay, error) {
-	date_day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	date_day := dayOf(date)
 	for k, day := range cal_days {
-		day_day := time.Date(day.Date.Year(), day.Date.Month(), day.Date.Day(), 0, 0, 0, 0, day.Date.Location())
-		if day_day.Equal(date_day) {
+		if dayOf(day.Date).Equal(date_day) {
 			return &cal_days[k], nil
 		}
 	}
@@ -158,14 +166,25 @@ func mergeIntoCalDays(cal_days []CalDay, event CalendarEvent) []CalDay {
 }
 
 func GetCalDays(fromDate time.Time, toDate time.Time) []CalDay {
+	return GetCalendarCalDays(fromDate, toDate, CalendarToInt("mahanikaya"))
+}
+
+// CalDays of a calendar, see CalendarToInt()
+func GetCalendarCalDays(fromDate time.Time, toDate time.Time, calendar int) []CalDay {
+	return GetCalendarCalDaysIn(fromDate, toDate, calendar, time.UTC)
+}
+
+// CalDays of a calendar, with the astronomical moons on their civil date in the
+// location, see GetAstroMoonsIn()
+func GetCalendarCalDaysIn(fromDate time.Time, toDate time.Time, calendar int, loc *time.Location) []CalDay {
 	var cal_days []CalDay
 
-	for _, d := range GetAstroMoons(fromDate, toDate) {
+	for _, d := range GetAstroMoonsIn(fromDate, toDate, loc) {
 		cal_days = mergeIntoCalDays(cal_days, d)
 	}
 
 	for year := fromDate.Year(); year <= toDate.Year(); year++ {
-		for _, d := range GenerateSolarYear(year) {
+		for _, d := range GenerateCalendarSolarYearIn(year, calendar, loc) {
 			if d.GetDate().Before(fromDate) || d.GetDate().After(toDate) {
 				continue
 			} else {
@@ -174,19 +193,30 @@ func GetCalDays(fromDate time.Time, toDate time.Time) []CalDay {
 		}
 	}
 
+	for _, d := range GetEclipses(fromDate, toDate, loc, CalDayEclipses) {
+		cal_days = mergeIntoCalDays(cal_days, d)
+	}
+
+	// Eclipses on the uposatha days
+	for i := range cal_days {
+		if len(cal_days[i].UposathaMoon) == 0 {
+			continue
+		}
+		for j := range cal_days[i].Eclipses {
+			cal_days[i].Eclipses[j].Uposatha = true
+		}
+	}
+
 	sort.Sort(CalDaySlice(cal_days))
 	return cal_days
 }
 
-func GenerateSolarYear(solar_year int) []CalendarEvent {
-	var events []CalendarEvent
-
-	var su_year SuriyaYear
-	su_year.Init(solar_year)
-
+// The Kattika Full Moon before the solar year, the last uposatha of the Vassāna
+// season, to start generating the uposathas of the year from.
+func PreviousKattikaUposatha(solar_year int) UposathaMoon {
 	date := CalculatePreviousKattika(solar_year)
 
-	last_uposatha := UposathaMoon{
+	return UposathaMoon{
 		Date:        date,
 		Calendar:    0, // mahanikaya
 		Phase:       "full",
@@ -198,17 +228,84 @@ func GenerateSolarYear(solar_year int) []CalendarEvent {
 		LunarSeason: 3,
 		LunarYear:   date.Year() + BEdiff,
 	}
+}
+
+// The uposathas of a calendar after the Kattika Full Moon before the solar
+// year, until the first one after the year.
+func calendarUposathas(solar_year int, calendar int, loc *time.Location) []UposathaMoon {
+	switch calendar {
+	case CalendarToInt("astronomical"):
+		return AstronomicalUposathas(solar_year, loc)
+	case CalendarToInt("myanmar"):
+		return MyanmarUposathas(solar_year)
+	case CalendarToInt("khmer"):
+		return KhmerUposathas(solar_year)
+	}
+
+	var uposathas []UposathaMoon
+
+	last_uposatha := PreviousKattikaUposatha(solar_year)
+	last_poya, err := last_uposatha.PoyaUposatha(last_uposatha)
+	if err != nil {
+		last_poya = last_uposatha
+	}
 
 	for last_uposatha.Date.Year() <= solar_year {
 		var uposatha UposathaMoon
 		uposatha = last_uposatha.NextUposatha()
 		last_uposatha = uposatha
 
+		// The Mahānikāya sequence continues from last_uposatha, the other
+		// calendars are derived from it.
+		switch calendar {
+		case CalendarToInt("dhammayut"):
+			uposatha = uposatha.DhammayutUposatha()
+		case CalendarToInt("srilanka"):
+			uposatha, err = uposatha.PoyaUposatha(last_poya)
+			if err != nil {
+				// Leave out the Poya rather than give a wrong day
+				if verbose {
+					log.Printf("%v\n", err)
+				}
+				continue
+			}
+			last_poya = uposatha
+		}
+
+		uposathas = append(uposathas, uposatha)
+	}
+
+	return uposathas
+}
+
+func GenerateSolarYear(solar_year int) []CalendarEvent {
+	return GenerateCalendarSolarYear(solar_year, CalendarToInt("mahanikaya"))
+}
+
+// Events of a solar year in a calendar, see CalendarToInt()
+func GenerateCalendarSolarYear(solar_year int, calendar int) []CalendarEvent {
+	return GenerateCalendarSolarYearIn(solar_year, calendar, time.UTC)
+}
+
+// Events of a solar year in a calendar, with the astronomical calendar in the
+// time of the location.
+func GenerateCalendarSolarYearIn(solar_year int, calendar int, loc *time.Location) []CalendarEvent {
+	var events []CalendarEvent
+
+	for _, uposatha := range calendarUposathas(solar_year, calendar, loc) {
+
 		// Uposatha
 
 		// assume confirmed
 		uposatha.Status = 2
 
+		// Thai almanacs label the year with its animal and decade
+		if uposatha.Date.Year() == solar_year && (calendar == CalendarToInt("mahanikaya") || calendar == CalendarToInt("dhammayut")) {
+			su := DateToNamedYear(uposatha.Date, YearNameChangeover)
+			uposatha.YearAnimal = su.AnimalName()
+			uposatha.YearDecade = su.DecadeName()
+		}
+
 		if uposatha.Date.Year() == solar_year {
 			events = append(events, uposatha)
 		}
@@ -238,12 +335,29 @@ func GenerateSolarYear(solar_year int) []CalendarEvent {
 
 			var e MajorEvent
 
+			if uposatha.Calendar == CalendarToInt("khmer") {
+				for _, e := range uposatha.KhmerMajorEvents() {
+					events = append(events, e)
+				}
+			}
+
+			// Full Moon Poya Days
+			if uposatha.Calendar == CalendarToInt("srilanka") && uposatha.Phase == "full" {
+				e = MajorEvent{
+					Date:        uposatha.Date,
+					Calendar:    uposatha.Calendar,
+					Summary:     uposatha.Name + " Day",
+					Description: uposatha.Name + " Day",
+				}
+				events = append(events, e)
+			}
+
 			if uposatha.Event == "magha" {
 				e = MajorEvent{
 					Date:        uposatha.Date,
 					Calendar:    uposatha.Calendar,
-					Summary:     "Māgha Pūjā",
-					Description: "Māgha Pūjā",
+					Summary:     majorEventName("Māgha Pūjā", uposatha.Calendar),
+					Description: majorEventName("Māgha Pūjā", uposatha.Calendar),
 				}
 				events = append(events, e)
 			}
@@ -252,8 +366,8 @@ func GenerateSolarYear(solar_year int) []CalendarEvent {
 				e = MajorEvent{
 					Date:        uposatha.Date,
 					Calendar:    uposatha.Calendar,
-					Summary:     "Vesākha Pūjā",
-					Description: "Vesākha Pūjā",
+					Summary:     majorEventName("Vesākha Pūjā", uposatha.Calendar),
+					Description: majorEventName("Vesākha Pūjā", uposatha.Calendar),
 				}
 				events = append(events, e)
 			}
@@ -262,16 +376,16 @@ func GenerateSolarYear(solar_year int) []CalendarEvent {
 				e = MajorEvent{
 					Date:        uposatha.Date,
 					Calendar:    uposatha.Calendar,
-					Summary:     "Āsāḷha Pūjā",
-					Description: "Āsāḷha Pūjā",
+					Summary:     majorEventName("Āsāḷha Pūjā", uposatha.Calendar),
+					Description: majorEventName("Āsāḷha Pūjā", uposatha.Calendar),
 				}
 				events = append(events, e)
 
 				e = MajorEvent{
 					Date:        uposatha.Date.AddDate(0, 0, 1),
 					Calendar:    uposatha.Calendar,
-					Summary:     "First day of Vassa",
-					Description: "First day of Vassa",
+					Summary:     majorEventName("First day of Vassa", uposatha.Calendar),
+					Description: majorEventName("First day of Vassa", uposatha.Calendar),
 				}
 				events = append(events, e)
 			}
@@ -281,8 +395,8 @@ func GenerateSolarYear(solar_year int) []CalendarEvent {
 				e = MajorEvent{
 					Date:        uposatha.Date,
 					Calendar:    uposatha.Calendar,
-					Summary:     "Pavāraṇā Day",
-					Description: "Pavāraṇā Day",
+					Summary:     majorEventName("Pavāraṇā Day", uposatha.Calendar),
+					Description: majorEventName("Pavāraṇā Day", uposatha.Calendar),
 				}
 				events = append(events, e)
 
@@ -290,13 +404,25 @@ func GenerateSolarYear(solar_year int) []CalendarEvent {
 				e = MajorEvent{
 					Date:        uposatha.Date,
 					Calendar:    uposatha.Calendar,
-					Summary:     "Last day of Vassa",
-					Description: "Last day of Vassa",
+					Summary:     majorEventName("Last day of Vassa", uposatha.Calendar),
+					Description: majorEventName("Last day of Vassa", uposatha.Calendar),
 				}
 				events = append(events, e)
 			}
 		}
 	}
 
+	// Songkran, in the calendars which observe it
+	switch calendar {
+	case CalendarToInt("mahanikaya"), CalendarToInt("dhammayut"), CalendarToInt("khmer"):
+		var su SuriyaYear
+		su.Init(solar_year)
+		for _, e := range su.Songkran().MajorEvents(calendar) {
+			if e.Date.Year() == solar_year {
+				events = append(events, e)
+			}
+		}
+	}
+
 	return events
 }
@@ -0,0 +1,201 @@
+package suriya
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	s "strings"
+	"time"
+)
+
+/*
+The deviation of the calculated uposathas from the astronomical moons.
+
+The offset in hours is from the middle of the uposatha day in the location to
+the instant of the nearest astronomical moon of the same phase, so that a moon
+during the uposatha day is within 12 hours. A positive offset means the moon
+comes after the uposatha. Days is the difference of their civil dates.
+*/
+
+type Deviation struct {
+	Date      time.Time // the uposatha
+	Phase     string
+	Event     string    `json:",omitempty"`
+	AstroDate time.Time // the astronomical moon
+	Hours     float64
+	Days      int
+}
+
+type DeviationStats struct {
+	Period       string // such as "2016" or "2001-2100"
+	Count        int
+	MeanHours    float64
+	MeanAbsHours float64
+	MaxAbsHours  float64
+	DayCounts    map[int]int // number of uposathas by Days
+}
+
+type DeviationReport struct {
+	Deviations []Deviation
+	Years      []DeviationStats
+	Centuries  []DeviationStats
+}
+
+// The deviations of the uposathas in the CalDays from the astronomical moons
+// among them. The moons are expected in the location of the CalDays, as from
+// GetCalendarCalDaysIn().
+func NewDeviationReport(cal_days []CalDay, loc *time.Location) DeviationReport {
+	var deviations []Deviation
+
+	var moons []AstroMoon
+	for _, day := range cal_days {
+		if m := day.GetAstroMoon(); m.Phase == "new" || m.Phase == "full" {
+			moons = append(moons, m)
+		}
+	}
+	sort.Sort(astroMoonsByDate(moons))
+
+	for _, day := range cal_days {
+		if len(day.UposathaMoon) == 0 {
+			continue
+		}
+		u := day.GetUposathaMoon()
+
+		i := nearestAstroMoon(moons, u.Date, u.Phase)
+		if i < 0 {
+			continue
+		}
+
+		date := dayOf(u.Date)
+		midday := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, loc)
+
+		deviations = append(deviations, Deviation{
+			Date:      date,
+			Phase:     u.Phase,
+			Event:     u.Event,
+			AstroDate: moons[i].Date.In(loc),
+			Hours:     moons[i].Date.Sub(midday).Hours(),
+			Days:      daysBetween(u.Date, moons[i].Date.In(loc)),
+		})
+	}
+
+	return deviationReport(deviations)
+}
+
+/*
+The deviation report of a calendar between the dates, with the moons in the
+location. The moons are taken from a few weeks around the range, so that the
+uposathas at its ends are matched as well.
+*/
+func GetDeviationReport(fromDate time.Time, toDate time.Time, calendar int, loc *time.Location) DeviationReport {
+	cal_days := GetCalendarCalDaysIn(fromDate.AddDate(0, 0, -20), toDate.AddDate(0, 0, 20), calendar, loc)
+
+	var deviations []Deviation
+	for _, d := range NewDeviationReport(cal_days, loc).Deviations {
+		if d.Date.Before(dayOf(fromDate)) || d.Date.After(toDate) {
+			continue
+		}
+		deviations = append(deviations, d)
+	}
+
+	return deviationReport(deviations)
+}
+
+// The report of the deviations with the statistics of the years and centuries
+func deviationReport(deviations []Deviation) DeviationReport {
+	var report DeviationReport
+
+	report.Deviations = deviations
+	sort.Sort(deviationsByDate(report.Deviations))
+
+	report.Years = deviationStats(report.Deviations, func(d Deviation) string {
+		return fmt.Sprintf("%d", d.Date.Year())
+	})
+	report.Centuries = deviationStats(report.Deviations, func(d Deviation) string {
+		first := int(floorDiv(int64(d.Date.Year()-1), 100))*100 + 1
+		return fmt.Sprintf("%d-%d", first, first+99)
+	})
+
+	return report
+}
+
+// Statistics of the deviations grouped by the period of each, in the order of
+// the deviations.
+func deviationStats(deviations []Deviation, period func(Deviation) string) []DeviationStats {
+	var stats []DeviationStats
+	index := make(map[string]int)
+
+	for _, d := range deviations {
+		p := period(d)
+		i, ok := index[p]
+		if !ok {
+			i = len(stats)
+			index[p] = i
+			stats = append(stats, DeviationStats{Period: p, DayCounts: make(map[int]int)})
+		}
+		st := &stats[i]
+		st.Count++
+		st.MeanHours += d.Hours
+		st.MeanAbsHours += math.Abs(d.Hours)
+		st.MaxAbsHours = math.Max(st.MaxAbsHours, math.Abs(d.Hours))
+		st.DayCounts[d.Days]++
+	}
+
+	for i := range stats {
+		stats[i].MeanHours /= float64(stats[i].Count)
+		stats[i].MeanAbsHours /= float64(stats[i].Count)
+	}
+
+	return stats
+}
+
+func (d Deviation) String() string {
+	event := ""
+	if len(d.Event) != 0 {
+		event = " " + d.Event
+	}
+	return fmt.Sprintf("%s %-4s %s %+7.2f h %+d d%s",
+		d.Date.Format("2006-01-02"), d.Phase, d.AstroDate.Format("2006-01-02 15:04 MST"), d.Hours, d.Days, event)
+}
+
+func (st DeviationStats) String() string {
+	var days []int
+	for k := range st.DayCounts {
+		days = append(days, k)
+	}
+	sort.Ints(days)
+
+	var counts []string
+	for _, k := range days {
+		counts = append(counts, fmt.Sprintf("%+d d: %d", k, st.DayCounts[k]))
+	}
+
+	return fmt.Sprintf("%s: %d uposathas, mean %+.2f h, mean abs %.2f h, max abs %.2f h, %s",
+		st.Period, st.Count, st.MeanHours, st.MeanAbsHours, st.MaxAbsHours, s.Join(counts, ", "))
+}
+
+func (report DeviationReport) String() string {
+	var lines []string
+
+	for _, d := range report.Deviations {
+		lines = append(lines, d.String())
+	}
+
+	lines = append(lines, "", "Years:")
+	for _, st := range report.Years {
+		lines = append(lines, st.String())
+	}
+
+	lines = append(lines, "", "Centuries:")
+	for _, st := range report.Centuries {
+		lines = append(lines, st.String())
+	}
+
+	return s.Join(lines, "\n") + "\n"
+}
+
+type deviationsByDate []Deviation
+
+func (a deviationsByDate) Len() int           { return len(a) }
+func (a deviationsByDate) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a deviationsByDate) Less(i, j int) bool { return a[i].Date.Before(a[j].Date) }
@@ -0,0 +1,110 @@
+package suriya
+
+import (
+	"time"
+)
+
+/*
+The Dhammayut order determines the uposatha days with the Pakkhagaṇanā, a
+reckoning of the fortnights (pakkha) instead of the days of the Suriyayatra.
+
+A pakkha is 15 days, or 14 days when it is short. There are 59 short pakkhas in
+a great cycle (sampayuha) of 251 pakkhas, which is 3706 days. The mean pakkha is
+14.76494 days, very close to half of the mean synodic month, so the uposathas
+stay with the Moon for a long time. With one short pakkha in each group (vagga),
+the sampayuha has 44 groups of four pakkhas and 15 groups of five.
+
+The lunar months, the adhikamāsa years and the seasons are the same as in the
+Mahānikāya calendar, only the uposatha days differ, usually by a day.
+
+The order's almanac arranges the groups with its own tables, from its own
+epoch, and neither is available here. The short pakkhas are spread evenly
+instead, counted from the mean New Moon of 2000 January 6. The end of each
+pakkha is put about a third of a day before the mean phase. This keeps every
+uposatha from 1990 to 2030 within a day of the Mahānikāya uposatha, and most
+on the day of the true phase in Bangkok. An uposatha can be a day apart from
+the almanac where its tables place a short pakkha differently.
+*/
+
+const (
+	PakkhaCycle      = 251  // pakkhas in a sampayuha
+	PakkhaCycleDays  = 3706 // days in a sampayuha
+	PakkhaCycleShort = 59   // short pakkhas in a sampayuha, 251*15 - 3706
+
+	// The epoch is a New Moon, the day in Bangkok of the mean New Moon of 2000
+	// January 6, 18:14 UT, lunation 0 in Meeus, Astronomical Algorithms.
+	pakkhaEpochStr = "2000 Jan 7"
+
+	// The pakkhas end 91/251 of a day, 0.36 days, before the mean phase
+	pakkhaEpochOffset = -91
+)
+
+// Days from the epoch to the end of the nth pakkha. Even numbered pakkhas end
+// on a New Moon, odd numbered ones on a Full Moon.
+func pakkhaDay(n int) int {
+	a := n*PakkhaCycleDays + pakkhaEpochOffset
+	d := a / PakkhaCycle
+	// Floor towards negative infinity for the pakkhas before the epoch.
+	if a%PakkhaCycle != 0 && a < 0 {
+		d -= 1
+	}
+	return d
+}
+
+func pakkhaEpoch() time.Time {
+	date, _ := time.Parse("2006 Jan 2", pakkhaEpochStr)
+	return date
+}
+
+// The number of the pakkha of the phase which ends nearest to the date.
+func pakkhaNumber(date time.Time, phase string) int {
+	days := int(date.Sub(pakkhaEpoch()).Hours() / 24)
+
+	n := days * PakkhaCycle / PakkhaCycleDays
+
+	best := 0
+	best_delta := -1
+	for i := n - 2; i <= n+2; i++ {
+		// New Moons are even, Full Moons are odd
+		if (i%2 == 0) != (phase == "new") {
+			continue
+		}
+		delta := pakkhaDay(i) - days
+		if delta < 0 {
+			delta = -delta
+		}
+		if best_delta == -1 || delta < best_delta {
+			best = i
+			best_delta = delta
+		}
+	}
+
+	return best
+}
+
+// Date of the Pakkhagaṇanā uposatha of the phase nearest to the date.
+func PakkhaUposathaDate(date time.Time, phase string) time.Time {
+	return pakkhaEpoch().AddDate(0, 0, pakkhaDay(pakkhaNumber(date, phase)))
+}
+
+// The Dhammayut uposatha which corresponds to a Mahānikāya uposatha.
+func (m UposathaMoon) DhammayutUposatha() UposathaMoon {
+	du := m
+	n := pakkhaNumber(m.Date, m.Phase)
+
+	du.Calendar = 1 // dhammayut
+	du.Date = pakkhaEpoch().AddDate(0, 0, pakkhaDay(n))
+	du.U_Days = pakkhaDay(n) - pakkhaDay(n-1)
+
+	// The month is from Full Moon to Full Moon, as in NextUposatha.
+	if du.Phase == "new" {
+		du.M_Days = pakkhaDay(n+1) - pakkhaDay(n-1)
+	} else {
+		du.M_Days = pakkhaDay(n) - pakkhaDay(n-2)
+	}
+
+	// There is no adhikavāra day, the short and long pakkhas keep with the Moon.
+	du.HasAdhikavara = false
+
+	return du
+}
@@ -0,0 +1,143 @@
+package suriya
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	s "strings"
+	"time"
+)
+
+/*
+The duang, the Thai horoscope chart of twelve rāsi. The bodies are placed in
+the rāsi of their true longitude, and marked with their traditional numbers:
+
+1 Sun, 2 Moon, 3 Mars, 4 Mercury, 5 Jupiter, 6 Venus, 7 Saturn, 8 Rahu, 9 Ketu
+
+The lagna (ascendant) needs the time of the day and is not placed.
+*/
+
+type DuangBody struct {
+	Number    int
+	Name      string
+	Longitude float64 // true longitude, degrees
+	Rasi      int     // 0-11
+	Ral       string  // Eade's notation
+}
+
+type Duang struct {
+	Date     time.Time
+	Horakhun int
+	Bodies   []DuangBody
+}
+
+// The duang of the SuriyaDay.
+func (suDay SuriyaDay) Duang() Duang {
+	sp := suDay.Planets()
+
+	var d Duang
+	d.Date = suDay.Date
+	d.Horakhun = suDay.Horakhun
+
+	positions := []PlanetPosition{sp.Sun, sp.Moon, sp.Mars, sp.Mercury, sp.Jupiter, sp.Venus, sp.Saturn, sp.Rahu, sp.Ketu}
+	names := []string{"Sun", "Moon", "Mars", "Mercury", "Jupiter", "Venus", "Saturn", "Rahu", "Ketu"}
+
+	for i, pos := range positions {
+		longitude := pos.True.Normalize()
+		x, _, _ := longitude.Parts()
+		d.Bodies = append(d.Bodies, DuangBody{
+			Number:    i + 1,
+			Name:      names[i],
+			Longitude: longitude.Degree(),
+			Rasi:      x,
+			Ral:       longitude.String(),
+		})
+	}
+
+	return d
+}
+
+// The duang of the date, only the year, month and day are used.
+func DateToDuang(date time.Time) Duang {
+	suDay := SuriyaDay{}
+	suDay.InitDate(date)
+	return suDay.Duang()
+}
+
+// Numbers of the bodies in the rāsi
+func (d Duang) RasiBodies(rasi int) []int {
+	var numbers []int
+	for _, b := range d.Bodies {
+		if b.Rasi == rasi {
+			numbers = append(numbers, b.Number)
+		}
+	}
+	return numbers
+}
+
+func numbersString(numbers []int) string {
+	var a []string
+	for _, n := range numbers {
+		a = append(a, fmt.Sprintf("%d", n))
+	}
+	return s.Join(a, " ")
+}
+
+// The duang as plain text, the rāsi with their bodies, then the list of bodies.
+func (d Duang) String() string {
+	buf := bytes.NewBufferString("")
+
+	fmt.Fprintf(buf, "Duang of %s, Horakhun %d\n\n", d.Date.Format("2006-01-02"), d.Horakhun)
+
+	for rasi := 0; rasi < 12; rasi++ {
+		fmt.Fprintf(buf, "%s\n", s.TrimRight(fmt.Sprintf("%2d %-9s| %s", rasi, RasiName(rasi), numbersString(d.RasiBodies(rasi))), " "))
+	}
+
+	buf.WriteString("\n")
+
+	for _, b := range d.Bodies {
+		fmt.Fprintf(buf, "%d %-8s %s\n", b.Number, b.Name, b.Ral)
+	}
+
+	return buf.String()
+}
+
+// The duang as an SVG image. The circle is divided into the twelve rāsi, Mesa
+// at the top and going counter-clockwise.
+func (d Duang) SVG() string {
+	const size = 400.0
+	const c = size / 2
+	const r = 180.0
+
+	buf := bytes.NewBufferString("")
+
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f">`+"\n", size, size, size, size)
+	fmt.Fprintf(buf, `<circle cx="%.0f" cy="%.0f" r="%.0f" fill="none" stroke="black"/>`+"\n", c, c, r)
+	fmt.Fprintf(buf, `<circle cx="%.0f" cy="%.0f" r="%.0f" fill="none" stroke="black"/>`+"\n", c, c, r/4)
+
+	// point on the circle at the angle, counter-clockwise from the top
+	point := func(deg float64, radius float64) (float64, float64) {
+		rad := deg * math.Pi / 180
+		return c - radius*math.Sin(rad), c - radius*math.Cos(rad)
+	}
+
+	for rasi := 0; rasi < 12; rasi++ {
+		// the boundaries of the rāsi
+		x1, y1 := point(float64(rasi)*30-15, r/4)
+		x2, y2 := point(float64(rasi)*30-15, r)
+		fmt.Fprintf(buf, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="black"/>`+"\n", x1, y1, x2, y2)
+
+		x, y := point(float64(rasi)*30, r*0.85)
+		fmt.Fprintf(buf, `<text x="%.1f" y="%.1f" font-size="11" text-anchor="middle">%s</text>`+"\n", x, y, RasiName(rasi))
+
+		numbers := d.RasiBodies(rasi)
+		if len(numbers) > 0 {
+			x, y = point(float64(rasi)*30, r*0.55)
+			fmt.Fprintf(buf, `<text x="%.1f" y="%.1f" font-size="16" text-anchor="middle">%s</text>`+"\n", x, y, numbersString(numbers))
+		}
+	}
+
+	buf.WriteString("</svg>\n")
+
+	return buf.String()
+}
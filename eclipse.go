@@ -0,0 +1,219 @@
+package suriya
+
+import (
+	"fmt"
+	"github.com/soh335/ical"
+	"math"
+	"sort"
+	s "strings"
+	"time"
+)
+
+/*
+Solar and lunar eclipses, by the modern method of Jean Meeus, "Astronomical
+Algorithms", 2nd ed., ch. 54.
+
+The Suriyayatra procedure which Eade describes takes Rahu and the diameters of
+the Sun, the Moon and the Earth's shadow from the Suriyayatra's own elements.
+These are not available here, and the eclipses are not approximated with other
+constants in the meantime. The Method keeps the eclipses of the two procedures
+apart when it is added.
+*/
+
+const (
+	EclipseSolar = "solar"
+	EclipseLunar = "lunar"
+
+	EclipseModern = "modern"
+)
+
+type Eclipse struct {
+	Date      time.Time // the maximum
+	Kind      string    // solar or lunar
+	Method    string    // modern
+	Type      string    // total, annular, hybrid, partial or penumbral
+	Magnitude float64
+	Gamma     float64 `json:",omitempty"` // least distance from the axis of the Moon's shadow or the Earth's shadow, in Earth radii
+	Uposatha  bool    `json:",omitempty"` // on an uposatha day of the calendar
+}
+
+func (e Eclipse) AddToDay(day_p *CalDay) {
+	day_p.Date = e.Date
+	day_p.Eclipses = append(day_p.Eclipses, e)
+	return
+}
+
+func (e Eclipse) GetDate() time.Time {
+	return e.Date
+}
+
+func (e Eclipse) String() string {
+	str := fmt.Sprintf("%s %s Eclipse", s.Title(e.Type), s.Title(e.Kind))
+	if e.Uposatha {
+		str += " on the uposatha"
+	}
+	return str
+}
+
+func (e Eclipse) IcalEvent() ical.VEvent {
+	return icalEvent(e)
+}
+
+// The eclipses of the methods between the dates, in order. The modern ones are
+// in the local time of the location.
+func GetEclipses(fromDate time.Time, toDate time.Time, loc *time.Location, methods []string) []Eclipse {
+	var eclipses []Eclipse
+
+	for _, method := range methods {
+		switch method {
+		case EclipseModern:
+			for _, e := range ModernEclipses(inLocation(fromDate, loc), inLocation(toDate, loc)) {
+				e.Date = e.Date.In(loc)
+				eclipses = append(eclipses, e)
+			}
+		}
+	}
+
+	sort.Sort(eclipsesByDate(eclipses))
+
+	return eclipses
+}
+
+// The modern eclipses between the dates, in UTC.
+func ModernEclipses(fromDate time.Time, toDate time.Time) []Eclipse {
+	var eclipses []Eclipse
+
+	for k := lunationOf(fromDate) - 1; ; k++ {
+		for _, kind := range []string{EclipseSolar, EclipseLunar} {
+			e, ok := modernEclipse(k, kind)
+			if !ok {
+				continue
+			}
+			if e.Date.After(toDate) {
+				return eclipses
+			}
+			if e.Date.Before(fromDate) {
+				continue
+			}
+			eclipses = append(eclipses, e)
+		}
+		if MoonPhaseTime(k, PhaseNew).After(toDate) {
+			return eclipses
+		}
+	}
+}
+
+// The eclipse at the New Moon (solar) or the Full Moon (lunar) of the
+// lunation k, if there is one. Meeus, ch. 54.
+func modernEclipse(k int, kind string) (Eclipse, bool) {
+	var e Eclipse
+
+	kf := float64(k)
+	if kind == EclipseLunar {
+		kf += 0.5
+	}
+
+	T := kf / 1236.85
+	T2 := T * T
+	T3 := T2 * T
+	T4 := T3 * T
+
+	F := 160.7108 + 390.67050284*kf - 0.0016118*T2 - 0.00000227*T3 + 0.000000011*T4
+	if math.Abs(sinDeg(F)) > 0.36 {
+		return e, false
+	}
+
+	jde := 2451550.09766 + 29.530588861*kf + 0.00015437*T2 - 0.000000150*T3 + 0.00000000073*T4
+	M := 2.5534 + 29.10535670*kf - 0.0000014*T2 - 0.00000011*T3
+	Mp := 201.5643 + 385.81693528*kf + 0.0107582*T2 + 0.00001238*T3 - 0.000000058*T4
+	Om := 124.7746 - 1.56375588*kf + 0.0020672*T2 + 0.00000215*T3
+	E := 1 - 0.002516*T - 0.0000074*T2
+
+	F1 := F - 0.02665*sinDeg(Om)
+	A1 := 299.77 + 0.107408*kf - 0.009173*T2
+
+	if kind == EclipseSolar {
+		jde += -0.4075*sinDeg(Mp) + 0.1721*E*sinDeg(M)
+	} else {
+		jde += -0.4065*sinDeg(Mp) + 0.1727*E*sinDeg(M)
+	}
+	jde += 0.0161*sinDeg(2*Mp) -
+		0.0097*sinDeg(2*F1) +
+		0.0073*E*sinDeg(Mp-M) -
+		0.0050*E*sinDeg(Mp+M) -
+		0.0023*sinDeg(Mp-2*F1) +
+		0.0021*E*sinDeg(2*M) +
+		0.0012*sinDeg(Mp+2*F1) +
+		0.0006*E*sinDeg(2*Mp+M) -
+		0.0004*sinDeg(3*Mp) -
+		0.0003*E*sinDeg(M+2*F1) +
+		0.0003*sinDeg(A1) -
+		0.0002*E*sinDeg(M-2*F1) -
+		0.0002*E*sinDeg(2*Mp-M) -
+		0.0002*sinDeg(Om)
+
+	P := 0.2070*E*sinDeg(M) + 0.0024*E*sinDeg(2*M) - 0.0392*sinDeg(Mp) + 0.0116*sinDeg(2*Mp) -
+		0.0073*E*sinDeg(Mp+M) + 0.0067*E*sinDeg(Mp-M) + 0.0118*sinDeg(2*F1)
+	Q := 5.2207 - 0.0048*E*cosDeg(M) + 0.0020*E*cosDeg(2*M) - 0.3299*cosDeg(Mp) -
+		0.0060*E*cosDeg(Mp+M) + 0.0041*E*cosDeg(Mp-M)
+	W := math.Abs(cosDeg(F1))
+
+	gamma := (P*cosDeg(F1) + Q*sinDeg(F1)) * (1 - 0.0048*W)
+	u := 0.0059 + 0.0046*E*cosDeg(M) - 0.0182*cosDeg(Mp) + 0.0004*cosDeg(2*Mp) - 0.0005*cosDeg(M+Mp)
+
+	g := math.Abs(gamma)
+
+	if kind == EclipseSolar {
+		if g > 1.5433+u {
+			return e, false
+		}
+		switch {
+		case g < 0.9972:
+			omega := 0.00464 * math.Sqrt(1-gamma*gamma)
+			switch {
+			case u < 0:
+				e.Type = "total"
+			case u > omega:
+				e.Type = "annular"
+			default:
+				e.Type = "hybrid"
+			}
+			e.Magnitude = 1
+		default:
+			e.Type = "partial"
+			e.Magnitude = (1.5433 + u - g) / (0.5461 + 2*u)
+		}
+	} else {
+		penumbral := (1.5573 + u - g) / 0.5450
+		umbral := (1.0128 - u - g) / 0.5450
+		switch {
+		case umbral >= 1:
+			e.Type = "total"
+			e.Magnitude = umbral
+		case umbral > 0:
+			e.Type = "partial"
+			e.Magnitude = umbral
+		case penumbral > 0:
+			e.Type = "penumbral"
+			e.Magnitude = penumbral
+		default:
+			return e, false
+		}
+	}
+
+	year := 2000 + (jde-2451544.5)/365.25
+
+	e.Date = JDToTime(jde - DeltaT(year)/86400)
+	e.Kind = kind
+	e.Method = EclipseModern
+	e.Magnitude = math.Floor(e.Magnitude*1000+0.5) / 1000
+	e.Gamma = math.Floor(gamma*10000+0.5) / 10000
+
+	return e, true
+}
+
+type eclipsesByDate []Eclipse
+
+func (a eclipsesByDate) Len() int           { return len(a) }
+func (a eclipsesByDate) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a eclipsesByDate) Less(i, j int) bool { return dayOf(a[i].Date).Before(dayOf(a[j].Date)) }
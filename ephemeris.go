@@ -0,0 +1,283 @@
+package suriya
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+/*
+Low precision modern positions of the Sun and the Moon, to measure the drift of
+the Suriyayatra over the centuries.
+
+The Sun is from Jean Meeus, "Astronomical Algorithms", 2nd ed., ch. 25, within
+0.01 degree. The Moon is the main terms of ch. 47, within about 0.02 degree.
+Both are the apparent tropical longitudes, and an Ayanamsa gives the sidereal
+longitudes to compare with the Suriyayatra.
+*/
+
+// The periodic terms of the Moon's longitude: the multiples of D, M, M', F and
+// the coefficient in 0.000001 degree. Meeus, Table 47.A, the terms above 0.002
+// degree.
+var moonLongitudeTerms = [][5]float64{
+	{0, 0, 1, 0, 6288774},
+	{2, 0, -1, 0, 1274027},
+	{2, 0, 0, 0, 658314},
+	{0, 0, 2, 0, 213618},
+	{0, 1, 0, 0, -185116},
+	{0, 0, 0, 2, -114332},
+	{2, 0, -2, 0, 58793},
+	{2, -1, -1, 0, 57066},
+	{2, 0, 1, 0, 53322},
+	{2, -1, 0, 0, 45758},
+	{0, 1, -1, 0, -40923},
+	{1, 0, 0, 0, -34720},
+	{0, 1, 1, 0, -30383},
+	{2, 0, 0, -2, 15327},
+	{0, 0, 1, 2, -12528},
+	{0, 0, 1, -2, 10980},
+	{4, 0, -1, 0, 10675},
+	{0, 0, 3, 0, 10034},
+	{4, 0, -2, 0, 8548},
+	{2, 1, -1, 0, -7888},
+	{2, 1, 0, 0, -6766},
+	{1, 0, -1, 0, -5163},
+	{1, 1, 0, 0, 4987},
+	{2, -1, 1, 0, 4036},
+	{2, 0, 2, 0, 3994},
+	{4, 0, 0, 0, 3861},
+	{2, 0, -3, 0, 3665},
+	{0, 1, -2, 0, -2689},
+	{2, 0, -1, 2, -2602},
+	{2, -1, -2, 0, 2390},
+	{1, 0, 1, 0, -2348},
+	{2, -2, 0, 0, 2236},
+	{0, 1, 2, 0, -2120},
+	{0, 2, 0, 0, -2069},
+	{2, -2, -1, 0, 2048},
+}
+
+// Julian centuries from J2000.0
+func julianCenturies(jde float64) float64 {
+	return (jde - 2451545.0) / 36525
+}
+
+// The longitude of the ascending node of the Moon's mean orbit
+func moonNode(T float64) float64 {
+	return 125.04452 - 1934.136261*T + 0.0020708*T*T + T*T*T/450000
+}
+
+// The nutation in longitude in degrees, with the main terms
+func nutationLongitude(T float64) float64 {
+	L := 280.4665 + 36000.7698*T
+	Lp := 218.3165 + 481267.8813*T
+	om := moonNode(T)
+	return (-17.20*sinDeg(om) - 1.32*sinDeg(2*L) - 0.23*sinDeg(2*Lp) + 0.21*sinDeg(2*om)) / 3600
+}
+
+// The true geometric longitude of the Sun, Meeus ch. 25
+func sunTrueLongitude(jde float64) float64 {
+	T := julianCenturies(jde)
+
+	L0 := 280.46646 + 36000.76983*T + 0.0003032*T*T
+	M := 357.52911 + 35999.05029*T - 0.0001537*T*T
+	C := (1.914602-0.004817*T-0.000014*T*T)*sinDeg(M) +
+		(0.019993-0.000101*T)*sinDeg(2*M) +
+		0.000289*sinDeg(3*M)
+
+	return normalizeLongitude(L0 + C)
+}
+
+// The geometric longitude of the Moon, Meeus ch. 47
+func moonTrueLongitude(jde float64) float64 {
+	T := julianCenturies(jde)
+	T2 := T * T
+	T3 := T2 * T
+	T4 := T3 * T
+
+	Lp := 218.3164477 + 481267.88123421*T - 0.0015786*T2 + T3/538841 - T4/65194000
+	D := 297.8501921 + 445267.1114034*T - 0.0018819*T2 + T3/545868 - T4/113065000
+	M := 357.5291092 + 35999.0502909*T - 0.0001536*T2 + T3/24490000
+	Mp := 134.9633964 + 477198.8675055*T + 0.0087414*T2 + T3/69699 - T4/14712000
+	F := 93.2720950 + 483202.0175233*T - 0.0036539*T2 - T3/3526000 + T4/863310000
+	E := 1 - 0.002516*T - 0.0000074*T2
+
+	var sum float64
+	for _, term := range moonLongitudeTerms {
+		c := term[4]
+		switch math.Abs(term[1]) {
+		case 1:
+			c *= E
+		case 2:
+			c *= E * E
+		}
+		sum += c * sinDeg(term[0]*D+term[1]*M+term[2]*Mp+term[3]*F)
+	}
+
+	A1 := 119.75 + 131.849*T
+	A2 := 53.09 + 479264.290*T
+	sum += 3958*sinDeg(A1) + 1962*sinDeg(Lp-F) + 318*sinDeg(A2)
+
+	return normalizeLongitude(Lp + sum/1000000)
+}
+
+// The apparent tropical longitude of the Sun in degrees at the time
+func SunLongitude(t time.Time) float64 {
+	jde := timeToJDE(t)
+	T := julianCenturies(jde)
+	// -0.00569 is the aberration
+	return normalizeLongitude(sunTrueLongitude(jde) + nutationLongitude(T) - 0.00569)
+}
+
+// The apparent tropical longitude of the Moon in degrees at the time
+func MoonLongitude(t time.Time) float64 {
+	jde := timeToJDE(t)
+	return normalizeLongitude(moonTrueLongitude(jde) + nutationLongitude(julianCenturies(jde)))
+}
+
+// The Julian Ephemeris Day of the time, with Delta T
+func timeToJDE(t time.Time) float64 {
+	jd := TimeToJD(t)
+	year := 2000 + (jd-2451544.5)/365.25
+	return jd + DeltaT(year)/86400
+}
+
+/*
+The ayanāṃśa in degrees at the time, the distance of the tropical zodiac from
+the sidereal one. Subtracted from a tropical longitude it gives the sidereal
+longitude.
+*/
+type Ayanamsa func(t time.Time) float64
+
+// The ayanāṃśa with the value at J2000.0, moving with the general precession
+// in longitude.
+func FixedAyanamsa(j2000 float64) Ayanamsa {
+	return func(t time.Time) float64 {
+		T := julianCenturies(TimeToJD(t))
+		return j2000 + (5028.796195*T+1.1054348*T*T)/3600
+	}
+}
+
+// Lahiri (Chitrapaksha), the ayanāṃśa of the Indian national calendar
+var LahiriAyanamsa = FixedAyanamsa(23.85305)
+
+// No ayanāṃśa, to compare with the tropical longitudes
+func TropicalAyanamsa(t time.Time) float64 {
+	return 0
+}
+
+// The Suriyayatra longitudes of a day compared with the modern ones. The
+// errors are Suriyayatra minus modern, within -180 and 180 degrees.
+type EphemerisComparison struct {
+	Date       time.Time // the time of the modern positions
+	Horakhun   int
+	Ayanamsa   float64
+	SuriyaSun  float64 // sidereal degrees
+	ModernSun  float64 // sidereal degrees
+	SunError   float64
+	SuriyaMoon float64
+	ModernMoon float64
+	MoonError  float64
+}
+
+type EphemerisOptions struct {
+	Ayanamsa   Ayanamsa       // defaults to LahiriAyanamsa
+	Location   *time.Location // defaults to the local mean time of Bangkok
+	TimeOfDay  time.Duration  // from SuriyaDawn after the civil day in Location
+	Arithmetic int            // FloatArithmetic or ExactArithmetic
+}
+
+// The time of the Suriyayatra positions of a day, from the midnight ending its
+// civil date.
+const SuriyaDawn = 6 * time.Hour
+
+/*
+The Suriyayatra Sun and Moon compared with the modern positions, every
+step_days from the date to the date.
+
+The day of a Horakhun is counted to its end, as in HorakhunToDate(), and the
+traditional day runs from dawn to dawn, so the positions are for the dawn after
+the civil date, SuriyaDawn in the local mean time of Location. TimeOfDay moves
+the modern positions from there, -SuriyaDawn for the midnight.
+*/
+func CompareEphemeris(fromDate time.Time, toDate time.Time, step_days int, opts EphemerisOptions) ([]EphemerisComparison, error) {
+	var rows []EphemerisComparison
+
+	if step_days < 1 {
+		return rows, fmt.Errorf("Invalid step, less than 1 day: %d", step_days)
+	}
+	if opts.Ayanamsa == nil {
+		opts.Ayanamsa = LahiriAyanamsa
+	}
+	if opts.Location == nil {
+		opts.Location = LongitudeLocation(100.5)
+	}
+
+	for date := dayOf(fromDate); !date.After(toDate); date = date.AddDate(0, 0, step_days) {
+		if _, err := NewSuriyaYear(date.Year()); err != nil {
+			return rows, err
+		}
+
+		var suDay SuriyaDay
+		suDay.InitDate(date)
+		if opts.Arithmetic == ExactArithmetic {
+			suDay.InitWith(suDay.Year, suDay.Day, ExactArithmetic)
+		}
+
+		t := time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, opts.Location).Add(SuriyaDawn + opts.TimeOfDay)
+		ayanamsa := opts.Ayanamsa(t)
+
+		row := EphemerisComparison{
+			Date:       t,
+			Horakhun:   suDay.Horakhun,
+			Ayanamsa:   ayanamsa,
+			SuriyaSun:  suDay.TrueSun.Normalize().Degree(),
+			ModernSun:  normalizeLongitude(SunLongitude(t) - ayanamsa),
+			SuriyaMoon: suDay.TrueMoon.Normalize().Degree(),
+			ModernMoon: normalizeLongitude(MoonLongitude(t) - ayanamsa),
+		}
+		row.SunError = longitudeDifference(row.SuriyaSun, row.ModernSun)
+		row.MoonError = longitudeDifference(row.SuriyaMoon, row.ModernMoon)
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// a - b within -180 and 180 degrees
+func longitudeDifference(a float64, b float64) float64 {
+	d := normalizeLongitude(a - b)
+	if d > 180 {
+		d -= 360
+	}
+	return d
+}
+
+// The comparison as CSV with a header row, the degrees with 4 decimals.
+func WriteEphemerisCSV(w io.Writer, rows []EphemerisComparison) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"date", "horakhun", "ayanamsa", "suriya_sun", "modern_sun", "sun_error", "suriya_moon", "modern_moon", "moon_error"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		record := []string{
+			r.Date.Format(time.RFC3339),
+			fmt.Sprintf("%d", r.Horakhun),
+		}
+		for _, v := range []float64{r.Ayanamsa, r.SuriyaSun, r.ModernSun, r.SunError, r.SuriyaMoon, r.ModernMoon, r.MoonError} {
+			record = append(record, fmt.Sprintf("%.4f", v))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,35 @@
+package suriya
+
+import (
+	"fmt"
+)
+
+/*
+The errors of the constructors, so that callers can check them with a type
+assertion or errors.As().
+*/
+
+const (
+	MinYear = CSdiff // CE 638, CS 0, the beginning of the era
+	MaxYear = 9999   // CE, the largest four digit year
+)
+
+// The year is outside MinYear and MaxYear.
+type YearRangeError struct {
+	Year int // Common Era
+}
+
+func (e YearRangeError) Error() string {
+	return fmt.Sprintf("Year CE %d is out of range, it must be from %d to %d", e.Year, MinYear, MaxYear)
+}
+
+// The lunar year day is outside the days of the year.
+type DayRangeError struct {
+	Year       int // Common Era
+	Day        int
+	YearLength int
+}
+
+func (e DayRangeError) Error() string {
+	return fmt.Sprintf("Day %d is out of range in CE %d, it must be from 0 to %d", e.Day, e.Year, e.YearLength-1)
+}
@@ -12,7 +12,7 @@ occasional notes.
 
 type Event struct {
 	Date        time.Time
-	Calendar    int // mahanikaya, dhammayut, srilanka, myanmar
+	Calendar    int // mahanikaya, dhammayut, srilanka, myanmar, khmer
 	Summary     string
 	Description string
 }
@@ -0,0 +1,100 @@
+package suriya
+
+import (
+	"fmt"
+	s "strings"
+)
+
+/*
+A worksheet of the calculation, recording the intermediate values of each
+step as in Eade's "Rules for Interpolation...", sections A, B and C.
+
+Angles are recorded in Eade's notation, such as "2; 19 : 28", so that they can
+be compared with the paper line by line. Where FloatArithmetic works with
+degrees, the degrees it used are recorded instead.
+*/
+
+type ExplainStep struct {
+	Step  string // the section and step in Eade, such as "C13"
+	Label string // what the value is
+	Value string
+}
+
+type Explanation struct {
+	Title string
+	Steps []ExplainStep
+}
+
+// Records a step, does nothing on a nil Explanation, so that the calculation
+// can be traced only when asked for.
+func (ex *Explanation) add(step string, label string, value interface{}) {
+	if ex == nil {
+		return
+	}
+
+	var str string
+	switch v := value.(type) {
+	case float64:
+		str = fmt.Sprintf("%.4f", v)
+	default:
+		str = fmt.Sprintf("%v", v)
+	}
+
+	ex.Steps = append(ex.Steps, ExplainStep{Step: step, Label: label, Value: str})
+}
+
+func (ex Explanation) String() string {
+	label_width := 0
+	for _, st := range ex.Steps {
+		if n := len([]rune(st.Label)); n > label_width {
+			label_width = n
+		}
+	}
+
+	lines := []string{ex.Title, ""}
+
+	section := ""
+	for _, st := range ex.Steps {
+		if len(st.Step) > 0 && st.Step[:1] != section {
+			section = st.Step[:1]
+			if len(lines) > 2 {
+				lines = append(lines, "")
+			}
+		}
+		pad := s.Repeat(" ", label_width-len([]rune(st.Label)))
+		lines = append(lines, fmt.Sprintf("%-4s %s%s  %s", st.Step, st.Label, pad, st.Value))
+	}
+
+	return s.Join(lines, "\n") + "\n"
+}
+
+// The SuriyaYear with the steps of its calculation.
+func ExplainYear(ce_year int) (SuriyaYear, Explanation) {
+	ex := Explanation{
+		Title: fmt.Sprintf("Astronomical New Year of CE %d, BE %d, CS %d", ce_year, ce_year+BEdiff, ce_year-CSdiff),
+	}
+
+	var su SuriyaYear
+	su.init(ce_year, &ex)
+
+	info := GetYearInfo(ce_year)
+	ex.add("Y", "Year kind", info.Kind())
+	ex.add("Y", "Year length", info.YearLength)
+	ex.add("Y", "Kattika of the previous year", info.Kattika.Format("2006-01-02"))
+	ex.add("Y", "Asalha Puja", info.AsalhaPuja.Format("2006-01-02"))
+
+	return su, ex
+}
+
+// The SuriyaDay with the steps of its calculation, with FloatArithmetic or
+// ExactArithmetic.
+func ExplainDay(ce_year int, lunar_year_day int, arithmetic int) (SuriyaDay, Explanation) {
+	ex := Explanation{
+		Title: fmt.Sprintf("Day %d of the lunar year CE %d, BE %d, CS %d", lunar_year_day, ce_year, ce_year+BEdiff, ce_year-CSdiff),
+	}
+
+	var suDay SuriyaDay
+	suDay.init(ce_year, lunar_year_day, arithmetic, &ex)
+
+	return suDay, ex
+}
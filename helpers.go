@@ -41,71 +41,68 @@ func NormalizeDegree(deg float64) float64 {
 	return deg - math.Floor(deg/360)*360
 }
 
+// The date of the Horakhun, at the last second of the day. See also
+// HorakhunToJDN() and HorakhunToJulian().
 func HorakhunToDate(horakhun int64) time.Time {
-	// Make sure it is not a pointer to horakhunRefDate, but is the same time.
-	date, _ := time.Parse("2006 Jan 2", horakhunRefStr)
-	// At midnight
-	date = date.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
-
-	var delta, stepDays, direction int64
-
-	// Duration is max 290 solar years. Increment the date in 290 year steps.
-
-	delta = horakhun - horakhunRef
-	if delta < 0 {
-		direction = -1
-	} else {
-		direction = 1
-	}
-	stepDays = 290 * 356
-	for math.Abs(float64(delta)) > float64(stepDays) {
-		date = date.Add(time.Duration(direction*stepDays) * time.Hour * 24)
-		delta += direction * -1 * stepDays
-	}
-	// Add any remaining delta.
-	date = date.Add(time.Duration(delta) * time.Hour * 24)
-
-	return date
+	return JDNToDate(HorakhunToJDN(horakhun)).Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 }
 
-/* TODO: Rewrite this to calculate based on year values. Stepping like this only
-works for a few years backward and forward. CE 1288 is off for example. */
-
 // Calculate the kattika full moon before this year
 func CalculatePreviousKattika(solar_year int) time.Time {
+	return GetYearInfo(solar_year).Kattika
+}
 
-	dFmt := "2006-01-02"
+// CalculatePreviousKattika() without the year table
+func calculatePreviousKattika(solar_year int) time.Time {
+	var su_year SuriyaYear
+	su_year.Init(solar_year)
 
-	// Step from a known Kattika date as epoch date
-	kattika_date, _ := time.Parse(dFmt, "2015-11-25")
+	// The Tithi is the age of the moon on the New Year's Day, so counting back
+	// Tithi days from the Horakhun gives the last day of the 4th month,
+	// Phagguna, the day before Citta 1.
+	horakhun := su_year.Horakhun - su_year.Tithi
 
-	// Determine the direction of stepping
-	var direction int
-	if kattika_date.Year() < solar_year-1 {
-		direction = 1
-	} else if kattika_date.Year() > solar_year-1 {
-		direction = -1
+	// In an adhikamāsa year with a young moon at the New Year (tithi 0-5), the
+	// New Year falls in Vesakha, and Citta began one month earlier.
+	if su_year.Tithi <= 5 {
+		horakhun -= 30
 	}
 
-	// Step in direction until the Kattika in the prev. solar year
-	for y := kattika_date.Year(); y != solar_year-1; y += direction {
-		var su_year SuriyaYear
-		var n int
-		if direction == 1 {
-			su_year.Init(y + 1)
-		} else {
-			su_year.Init(y)
-		}
-		n = 6*29 + 6*30
-		if su_year.Is_Adhikamasa() {
-			n += 30
-		} else if su_year.Is_Adhikavara() {
-			n += 1
+	// The adhikavāra day of the last year was carried over to this year, so
+	// the calendar is one day behind the mean moon until Jettha.
+	if su_year.hasCarriedAdhikavara() {
+		horakhun -= 1
+	}
+
+	// Official calendars which added or omitted an adhikavāra day shift every
+	// Kattika after them.
+	if UseExceptions {
+		for year, is_adhikavara := range AdhikavaraExceptions {
+			if year >= solar_year {
+				continue
+			}
+			var check SuriyaYear
+			check.Init(year)
+			if is_adhikavara && !check.isRegularAdhikavara() {
+				horakhun += 1
+			} else if !is_adhikavara && check.isRegularAdhikavara() {
+				horakhun -= 1
+			}
 		}
-		kattika_date = kattika_date.Add(time.Duration(n*direction) * time.Hour * 24)
 	}
 
-	return kattika_date
+	// Kattika Full Moon to the end of Phagguna:
+	// Kattika   New         = 15
+	// Magasira  Full + New  = 15+14
+	// Phussa    Full + New  = 15+15
+	// Magha     Full + New  = 15+14
+	// Phagguna  Full + New  = 15+15
+	// -----------------------------
+	//                       = 133
+	horakhun -= 133
+
+	date := HorakhunToDate(int64(horakhun))
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 }
 
 var monthToInt = map[string]int{
@@ -129,6 +126,27 @@ func MonthToInt(month string) int {
 	return monthToInt[month]
 }
 
+var monthName = map[int]string{
+	0:  "",
+	1:  "Māgasira",
+	2:  "Phussa",
+	3:  "Māgha",
+	4:  "Phagguna",
+	5:  "Citta",
+	6:  "Vesākha",
+	7:  "Jeṭṭha",
+	8:  "Āsāḷha",
+	9:  "Sāvana",
+	10: "Bhaddapada",
+	11: "Assayuja",
+	12: "Kattika",
+	13: "2nd Āsāḷha",
+}
+
+func MonthName(number int) string {
+	return monthName[number]
+}
+
 var seasonToInt = map[string]int{
 	"null":    0,
 	"hemanta": 1,
@@ -151,17 +169,51 @@ func SeasonName(number int) string {
 	return seasonName[number]
 }
 
+var rasiName = map[int]string{
+	0:  "Mesa",
+	1:  "Usabha",
+	2:  "Methuna",
+	3:  "Kakkaṭa",
+	4:  "Sīha",
+	5:  "Kaññā",
+	6:  "Tulā",
+	7:  "Vicchika",
+	8:  "Dhanu",
+	9:  "Makara",
+	10: "Kumbha",
+	11: "Mīna",
+}
+
+func RasiName(number int) string {
+	return rasiName[number]
+}
+
 var calendarToInt = map[string]int{
-	"mahanikaya": 0,
-	"dhammayut":  1,
-	"srilanka":   2,
-	"myanmar":    3,
+	"mahanikaya":   0,
+	"dhammayut":    1,
+	"srilanka":     2,
+	"myanmar":      3,
+	"khmer":        4,
+	"astronomical": 5,
 }
 
 func CalendarToInt(calendar string) int {
 	return calendarToInt[calendar]
 }
 
+var calendarName = map[int]string{
+	0: "Mahānikāya",
+	1: "Dhammayut",
+	2: "Sri Lanka",
+	3: "Myanmar",
+	4: "Khmer",
+	5: "Astronomical",
+}
+
+func CalendarName(number int) string {
+	return calendarName[number]
+}
+
 var statusToInt = map[string]int{
 	"draft":     0,
 	"predicted": 1,
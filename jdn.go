@@ -0,0 +1,101 @@
+package suriya
+
+import (
+	"time"
+)
+
+/*
+Conversions between the Horakhun, the Julian Day Number and the civil dates in
+the proleptic Gregorian and Julian calendars, in integer arithmetic.
+
+The JDN is the number of the day which begins at noon. Horakhun 0 is JDN
+1954167, 24 March 638 in the proleptic Gregorian and 21 March 638 in the
+Julian calendar. Negative Horakhun values are the days before the era.
+
+The years are astronomical, year 0 is 1 BCE.
+*/
+
+const (
+	HorakhunJDNDiff = 1954167 // JDN of Horakhun 0
+	unixEpochJDN    = 2440588 // JDN of 1970 Jan 1
+)
+
+// Division which rounds towards negative infinity
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func HorakhunToJDN(horakhun int64) int64 {
+	return horakhun + HorakhunJDNDiff
+}
+
+func JDNToHorakhun(jdn int64) int64 {
+	return jdn - HorakhunJDNDiff
+}
+
+// Julian Day Number of the day of the date, in the proleptic Gregorian calendar
+func DateToJDN(date time.Time) int64 {
+	return GregorianToJDN(date.Year(), int(date.Month()), date.Day())
+}
+
+// Date at midnight UTC of the Julian Day Number
+func JDNToDate(jdn int64) time.Time {
+	return time.Unix((jdn-unixEpochJDN)*86400, 0).UTC()
+}
+
+// The Horakhun of the day of the date, the inverse of HorakhunToDate()
+func DateToHorakhun(date time.Time) int64 {
+	return JDNToHorakhun(DateToJDN(date))
+}
+
+func GregorianToJDN(year, month, day int) int64 {
+	y, m, d := int64(year), int64(month), int64(day)
+	a := floorDiv(14-m, 12)
+	y = y + 4800 - a
+	m = m + 12*a - 3
+	return d + floorDiv(153*m+2, 5) + 365*y + floorDiv(y, 4) - floorDiv(y, 100) + floorDiv(y, 400) - 32045
+}
+
+func JulianToJDN(year, month, day int) int64 {
+	y, m, d := int64(year), int64(month), int64(day)
+	a := floorDiv(14-m, 12)
+	y = y + 4800 - a
+	m = m + 12*a - 3
+	return d + floorDiv(153*m+2, 5) + 365*y + floorDiv(y, 4) - 32083
+}
+
+func JDNToGregorian(jdn int64) (year, month, day int) {
+	a := jdn + 32044
+	b := floorDiv(4*a+3, 146097)
+	c := a - floorDiv(146097*b, 4)
+	return jdnToCivil(100*b, c)
+}
+
+func JDNToJulian(jdn int64) (year, month, day int) {
+	return jdnToCivil(0, jdn+32082)
+}
+
+// The common steps of the Gregorian and Julian conversions, with the elapsed
+// centuries as years and the remaining days
+func jdnToCivil(years int64, c int64) (year, month, day int) {
+	d := floorDiv(4*c+3, 1461)
+	e := c - floorDiv(1461*d, 4)
+	m := floorDiv(5*e+2, 153)
+
+	day = int(e - floorDiv(153*m+2, 5) + 1)
+	month = int(m + 3 - 12*floorDiv(m, 10))
+	year = int(years + d - 4800 + floorDiv(m, 10))
+	return year, month, day
+}
+
+func HorakhunToJulian(horakhun int64) (year, month, day int) {
+	return JDNToJulian(HorakhunToJDN(horakhun))
+}
+
+func JulianToHorakhun(year, month, day int) int64 {
+	return JDNToHorakhun(JulianToJDN(year, month, day))
+}
@@ -0,0 +1,197 @@
+package suriya
+
+/*
+The Khmer calendar, reckoned with the Chhankitek.
+
+The Chhankitek calculates the values of the year as the Suriyayatra does, the
+Aharkun is the Horakhun, the Kromathupul is the Kammacubala, and the Bodithey
+is the Tithi. Its rules for the extra month (adhikameas) and the extra day
+(adhikavereak) are stated differently:
+
+- The year has an extra month if the Bodithey is 25 or more, or 5 or less.
+  Of consecutive years with 25 and 5, only the year with 5 has it. Of
+  consecutive years with 24 and 6, the year with 24 has it.
+
+- The year has an extra day if the Avoman is 126 or less in a solar leap year,
+  or 137 or less in a common year, except if the Avoman of the next year is 0.
+
+- If a year would have both, it has the extra month, and the extra day is
+  added to the next year.
+
+These give the same years as the rules of SuriyaYear with its
+AdhikavaraExceptions, for every year from CE 639 to 4000. The Bodithey of the
+next year is 11 more, so a 24 is followed by a 5 or a 6, and both rules choose
+the same year of the pair. A common year with the Avoman 137, such as 2014, is
+followed by 0, which the Suriyayatra has as Avoman less than 137. KhmerYear
+reckons with SuriyaYear.
+
+The extra month is Tutiyasadh, the second Asadh, and the extra day is added to
+Jesth, as in the Thai calendar.
+*/
+
+const (
+	KhmerBEdiff = 544 // Absolute of the Khmer BE - CE Era difference
+)
+
+var khmerMonthName = map[int]string{
+	0:  "",
+	1:  "Mikasar",
+	2:  "Bos",
+	3:  "Meak",
+	4:  "Phalkun",
+	5:  "Cheth",
+	6:  "Pisak",
+	7:  "Jesth",
+	8:  "Asadh",
+	9:  "Srap",
+	10: "Phatrabot",
+	11: "Assoch",
+	12: "Kadeuk",
+	13: "Tutiyasadh",
+}
+
+func KhmerMonthName(number int) string {
+	return khmerMonthName[number]
+}
+
+type KhmerYear struct {
+	Year        int // Common Era
+	BE_Year     int // Buddhist Era, CE + 544
+	JS_Year     int // Jolak Sakaraj, the same as Chulasakkarat
+	Aharkun     int
+	Kromthupul  int
+	Avoman      int
+	Bodithey    int
+	IsSolarLeap bool
+}
+
+func (ky *KhmerYear) Init(ce_year int) {
+	var su SuriyaYear
+	su.Init(ce_year)
+
+	ky.Year = ce_year
+	ky.BE_Year = ce_year + KhmerBEdiff
+	ky.JS_Year = su.CS_Year
+	ky.Aharkun = su.Horakhun
+	ky.Kromthupul = su.Kammacubala
+	ky.Avoman = su.Avoman
+	ky.Bodithey = su.Tithi
+	ky.IsSolarLeap = su.Is_Suriya_Leap()
+}
+
+func (ky KhmerYear) suriyaYear() SuriyaYear {
+	var su SuriyaYear
+	su.Init(ky.Year)
+	return su
+}
+
+// Whether the year has Tutiyasadh, the extra month
+func (ky KhmerYear) Is_Leap_Month() bool {
+	return ky.suriyaYear().Is_Adhikamasa()
+}
+
+// Whether the year has the extra day, also when it is carried from the last year
+func (ky KhmerYear) Is_Leap_Day() bool {
+	return ky.suriyaYear().Is_Adhikavara()
+}
+
+// Length of the lunar year in days
+func (ky KhmerYear) YearLength() int {
+	return ky.suriyaYear().YearLength()
+}
+
+// The next Khmer uposatha, the Thinh Sel days of the Full and New Moon.
+func (last_uposatha UposathaMoon) NextKhmerUposatha() UposathaMoon {
+	var ky KhmerYear
+	ky.Init(last_uposatha.Date.Year())
+
+	nu := last_uposatha.nextUposatha(ky.Is_Leap_Month(), ky.Is_Leap_Day())
+	nu.Calendar = 4 // khmer
+
+	// The major moons don't shift in the years with an extra month.
+	nu.Event = ""
+	if nu.Phase == "full" {
+		switch nu.LunarMonth {
+		case 3:
+			nu.Event = "magha"
+		case 6:
+			nu.Event = "vesakha"
+		case 11:
+			nu.Event = "pavarana"
+		case 8:
+			if !ky.Is_Leap_Month() {
+				nu.Event = "asalha"
+			}
+		case 13:
+			nu.Event = "asalha"
+		}
+	}
+
+	return nu
+}
+
+// The Khmer uposathas from the Kattika Full Moon before the solar year, until
+// the first one after it.
+func KhmerUposathas(solar_year int) []UposathaMoon {
+	var uposathas []UposathaMoon
+
+	last_uposatha := PreviousKattikaUposatha(solar_year)
+	last_uposatha.Calendar = 4 // khmer
+
+	for last_uposatha.Date.Year() <= solar_year {
+		last_uposatha = last_uposatha.NextKhmerUposatha()
+		uposathas = append(uposathas, last_uposatha)
+	}
+
+	return uposathas
+}
+
+var khmerEventName = map[string]string{
+	"Māgha Pūjā":         "Meak Bochea",
+	"Vesākha Pūjā":       "Visak Bochea",
+	"Āsāḷha Pūjā":        "Asadh Bochea",
+	"First day of Vassa": "Chol Vassa",
+	"Pavāraṇā Day":       "Pavarana Day",
+	"Last day of Vassa":  "Chenh Vassa",
+	"Maha Songkran":      "Moha Sangkran",
+	"Wan Nao":            "Virak Vanabat",
+	"Wan Thaloeng Sok":   "Virak Loeng Sak",
+}
+
+// Name of a major event in the calendar
+func majorEventName(name string, calendar int) string {
+	if calendar == CalendarToInt("khmer") {
+		if n, ok := khmerEventName[name]; ok {
+			return n
+		}
+	}
+	return name
+}
+
+// Pchum Ben is on the New Moon at the end of Phatrabot, and the fifteen days of
+// Kan Ben before it begin on the first day of the waning moon.
+func (m UposathaMoon) KhmerMajorEvents() []MajorEvent {
+	var events []MajorEvent
+
+	// The New Moon which ends Phatrabot begins Assoch
+	if m.Phase == "new" && m.LunarMonth == 11 {
+		events = append(events, MajorEvent{
+			Date:        m.Date,
+			Calendar:    m.Calendar,
+			Summary:     "Pchum Ben",
+			Description: "Pchum Ben",
+		})
+	}
+
+	// Kan Ben begins after the Full Moon of Phatrabot
+	if m.Phase == "full" && m.LunarMonth == 10 {
+		events = append(events, MajorEvent{
+			Date:        m.Date.AddDate(0, 0, 1),
+			Calendar:    m.Calendar,
+			Summary:     "First day of Kan Ben",
+			Description: "First day of Kan Ben",
+		})
+	}
+
+	return events
+}
@@ -0,0 +1,161 @@
+package suriya
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+/*
+A day in the Thai lunar calendar, such as "waning 8, month 6, BE 2569".
+
+A lunar month begins on the day after a New Moon with the waxing fortnight,
+and ends on the New Moon at the end of the waning fortnight. The uposatha
+sequence of NextUposatha labels a New Moon with the month which it begins,
+so the days after an uposatha are in the LunarMonth of that uposatha.
+*/
+
+type LunarDate struct {
+	Date        time.Time
+	BE_Year     int    // Buddhist Era, the lunar year of the uposathas
+	CS_Year     int    // Chulasakkarat Era, changes on the astronomical New Year
+	LunarMonth  int    // 1-12, 13 is 2nd Asalha (adhikamasa)
+	Phase       string // waxing or waning
+	Day         int    // day of the fortnight, 1-15
+	M_Days      int    // month days, 29 or 30
+	LunarSeason int    // 1-3, an int code to an []string array of names
+	S_Number    int    // the uposatha of the season which ends the fortnight, 1 of 8 in Hemanta
+	S_Total     int    // total number of uposathas in the season
+	IsUposatha  bool
+}
+
+// Find the uposathas before and on or after the date, i.e. the fortnight which
+// contains it.
+func fortnightOfDate(date time.Time) (last_uposatha UposathaMoon, next_uposatha UposathaMoon) {
+	last_uposatha = PreviousKattikaUposatha(date.Year() + 1)
+	if !last_uposatha.Date.Before(date) {
+		last_uposatha = PreviousKattikaUposatha(date.Year())
+	}
+
+	next_uposatha = last_uposatha.NextUposatha()
+	for next_uposatha.Date.Before(date) {
+		last_uposatha = next_uposatha
+		next_uposatha = last_uposatha.NextUposatha()
+	}
+
+	return last_uposatha, next_uposatha
+}
+
+// Convert a date to the Thai lunar date. Only the year, month and day of the
+// date are used.
+func DateToLunarDate(date time.Time) LunarDate {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	lu, nu := fortnightOfDate(date)
+
+	return lunarDateInFortnight(date, lu, nu)
+}
+
+// The lunar date of a day in the fortnight after the uposatha lu and until the
+// uposatha nu.
+func lunarDateInFortnight(date time.Time, lu UposathaMoon, nu UposathaMoon) LunarDate {
+	var ld LunarDate
+	ld.Date = date
+
+	// After a New Moon, the waxing fortnight of the month it begins. After a
+	// Full Moon, the waning fortnight of the same month.
+	// The waxing fortnight is always 15 days, the New Moon at the end of the
+	// month carries the length of the waning fortnight.
+	ld.LunarMonth = lu.LunarMonth
+	if lu.Phase == "new" {
+		ld.Phase = "waxing"
+		ld.M_Days = 15 + nu.NextUposatha().U_Days
+	} else {
+		ld.Phase = "waning"
+		ld.M_Days = 15 + nu.U_Days
+	}
+	ld.Day = int(date.Sub(lu.Date).Hours() / 24)
+	ld.IsUposatha = date.Equal(nu.Date)
+
+	// The season and uposatha number of the fortnight are of the uposatha
+	// which ends it, but the lunar year changes only with the first month.
+	ld.LunarSeason = nu.LunarSeason
+	ld.S_Number = nu.S_Number
+	ld.S_Total = nu.S_Total
+	ld.BE_Year = lu.LunarYear
+
+	// The CS year begins on the astronomical New Year's Day.
+	var su_year SuriyaYear
+	su_year.Init(date.Year())
+	new_year := HorakhunToDate(int64(su_year.Horakhun))
+	new_year = time.Date(new_year.Year(), new_year.Month(), new_year.Day(), 0, 0, 0, 0, time.UTC)
+	if date.Before(new_year) {
+		ld.CS_Year = su_year.CS_Year - 1
+	} else {
+		ld.CS_Year = su_year.CS_Year
+	}
+
+	return ld
+}
+
+// Convert a Thai lunar date to the date. Only the BE_Year, LunarMonth, Phase
+// and Day are used. Returns an error if there is no such day in the calendar,
+// such as waning 15 in a 29 day month, or 2nd Asalha in a year which is not
+// adhikamāsa.
+func LunarDateToDate(ld LunarDate) (time.Time, error) {
+	if ld.LunarMonth < 1 || ld.LunarMonth > 13 {
+		return time.Time{}, fmt.Errorf("Invalid lunar month: %d", ld.LunarMonth)
+	}
+
+	var phase string
+	switch ld.Phase {
+	case "waxing":
+		phase = "new"
+	case "waning":
+		phase = "full"
+	default:
+		return time.Time{}, fmt.Errorf("Invalid phase: %s", ld.Phase)
+	}
+
+	if ld.Day < 1 || ld.Day > 15 {
+		return time.Time{}, fmt.Errorf("Invalid day of the fortnight: %d", ld.Day)
+	}
+
+	// Months 1-12 of the lunar year follow the Kattika of the previous solar year.
+	solar_year := ld.BE_Year - BEdiff
+
+	if ld.LunarMonth == 13 {
+		var su_year SuriyaYear
+		su_year.Init(solar_year)
+		if !su_year.Is_Adhikamasa() {
+			return time.Time{}, fmt.Errorf("BE %d is not adhikamāsa, there is no 2nd Asalha", ld.BE_Year)
+		}
+	}
+
+	// The fortnight begins after the uposatha with the same month, the New Moon
+	// for the waxing and the Full Moon for the waning fortnight.
+	lu := PreviousKattikaUposatha(solar_year)
+	for !(lu.LunarYear == ld.BE_Year && lu.LunarMonth == ld.LunarMonth && lu.Phase == phase) {
+		if lu.LunarYear > ld.BE_Year {
+			return time.Time{}, errors.New("Lunar month not found")
+		}
+		lu = lu.NextUposatha()
+	}
+
+	nu := lu.NextUposatha()
+	if ld.Day > nu.U_Days {
+		return time.Time{}, fmt.Errorf("%s %d does not exist in %s BE %d, it has %d days",
+			ld.Phase, ld.Day, MonthName(ld.LunarMonth), ld.BE_Year, nu.U_Days)
+	}
+
+	return lu.Date.AddDate(0, 0, ld.Day), nil
+}
+
+func (ld LunarDate) String() string {
+	if ld.Day == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s %d, month %d (%s), BE %d, CS %d, %s %d/%d",
+		ld.Phase, ld.Day, ld.LunarMonth, MonthName(ld.LunarMonth), ld.BE_Year, ld.CS_Year,
+		SeasonName(ld.LunarSeason), ld.S_Number, ld.S_Total)
+}
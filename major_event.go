@@ -13,6 +13,7 @@ The major calendar events for convenient access.
 - Vassa begins
 - Pavarana Day
 - Vassa ends
+- Songkran
 */
 
 type MajorEvent Event
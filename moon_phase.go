@@ -0,0 +1,279 @@
+package suriya
+
+import (
+	"math"
+	"time"
+)
+
+/*
+The instants of the Moon phases, with the algorithm in Jean Meeus,
+"Astronomical Algorithms", 2nd ed., ch. 49.
+
+The error is within a minute or two for the present centuries. For the
+distant past and future, the uncertainty of Delta T (the difference of the
+Earth's rotation from uniform time) is larger than the error of the algorithm.
+*/
+
+const (
+	PhaseNew          = 0
+	PhaseFirstQuarter = 1
+	PhaseFull         = 2
+	PhaseLastQuarter  = 3
+
+	unixEpochJD = 2440587.5
+)
+
+var newMoonTerms = []float64{
+	-0.40720, 0.17241, 0.01608, 0.01039, 0.00739, -0.00514, 0.00208, -0.00111,
+	-0.00057, 0.00056, -0.00042, 0.00042, 0.00038, -0.00024, -0.00017, -0.00007,
+	0.00004, 0.00004, 0.00003, 0.00003, -0.00003, 0.00003, -0.00002, -0.00002,
+	0.00002,
+}
+
+var fullMoonTerms = []float64{
+	-0.40614, 0.17302, 0.01614, 0.01043, 0.00734, -0.00515, 0.00209, -0.00111,
+	-0.00057, 0.00056, -0.00042, 0.00042, 0.00038, -0.00024, -0.00017, -0.00007,
+	0.00004, 0.00004, 0.00003, 0.00003, -0.00003, 0.00003, -0.00002, -0.00002,
+	0.00002,
+}
+
+// The coefficients and rates of the planetary arguments A1 to A14
+var planetaryTerms = [][3]float64{
+	{299.77, 0.107408, 0.000325},
+	{251.88, 0.016321, 0.000165},
+	{251.83, 26.651886, 0.000164},
+	{349.42, 36.412478, 0.000126},
+	{84.66, 18.206239, 0.000110},
+	{141.74, 53.303771, 0.000062},
+	{207.14, 2.453732, 0.000060},
+	{154.84, 7.306860, 0.000056},
+	{34.52, 27.261239, 0.000047},
+	{207.19, 0.121824, 0.000042},
+	{291.34, 1.844379, 0.000040},
+	{161.72, 24.198154, 0.000037},
+	{239.56, 25.513099, 0.000035},
+	{331.55, 3.592518, 0.000023},
+}
+
+func sinDeg(deg float64) float64 {
+	return math.Sin(deg * math.Pi / 180)
+}
+
+func cosDeg(deg float64) float64 {
+	return math.Cos(deg * math.Pi / 180)
+}
+
+// Julian Ephemeris Day of the phase of the lunation k, counted from the New
+// Moon of 2000 Jan 6. Meeus, ch. 49.
+func moonPhaseJDE(k int, phase int) float64 {
+	kf := float64(k) + float64(phase)/4
+	T := kf / 1236.85
+	T2 := T * T
+	T3 := T2 * T
+	T4 := T3 * T
+
+	jde := 2451550.09766 + 29.530588861*kf + 0.00015437*T2 - 0.000000150*T3 + 0.00000000073*T4
+
+	E := 1 - 0.002516*T - 0.0000074*T2
+	M := 2.5534 + 29.10535670*kf - 0.0000014*T2 - 0.00000011*T3
+	Mp := 201.5643 + 385.81693528*kf + 0.0107582*T2 + 0.00001238*T3 - 0.000000058*T4
+	F := 160.7108 + 390.67050284*kf - 0.0016118*T2 - 0.00000227*T3 + 0.000000011*T4
+	Om := 124.7746 - 1.56375588*kf + 0.0020672*T2 + 0.00000215*T3
+
+	var corr float64
+
+	switch phase {
+	case PhaseNew, PhaseFull:
+		c := newMoonTerms
+		if phase == PhaseFull {
+			c = fullMoonTerms
+		}
+		args := []float64{
+			sinDeg(Mp),
+			E * sinDeg(M),
+			sinDeg(2 * Mp),
+			sinDeg(2 * F),
+			E * sinDeg(Mp-M),
+			E * sinDeg(Mp+M),
+			E * E * sinDeg(2*M),
+			sinDeg(Mp - 2*F),
+			sinDeg(Mp + 2*F),
+			E * sinDeg(2*Mp+M),
+			sinDeg(3 * Mp),
+			E * sinDeg(M+2*F),
+			E * sinDeg(M-2*F),
+			E * sinDeg(2*Mp-M),
+			sinDeg(Om),
+			sinDeg(Mp + 2*M),
+			sinDeg(2*Mp - 2*F),
+			sinDeg(3 * M),
+			sinDeg(Mp + M - 2*F),
+			sinDeg(2*Mp + 2*F),
+			sinDeg(Mp + M + 2*F),
+			sinDeg(Mp - M + 2*F),
+			sinDeg(Mp - M - 2*F),
+			sinDeg(3*Mp + M),
+			sinDeg(4 * Mp),
+		}
+		for i := range args {
+			corr += c[i] * args[i]
+		}
+
+	case PhaseFirstQuarter, PhaseLastQuarter:
+		corr = -0.62801*sinDeg(Mp) +
+			0.17172*E*sinDeg(M) -
+			0.01183*E*sinDeg(Mp+M) +
+			0.00862*sinDeg(2*Mp) +
+			0.00804*sinDeg(2*F) +
+			0.00454*E*sinDeg(Mp-M) +
+			0.00204*E*E*sinDeg(2*M) -
+			0.00180*sinDeg(Mp-2*F) -
+			0.00070*sinDeg(Mp+2*F) -
+			0.00040*sinDeg(3*Mp) -
+			0.00034*E*sinDeg(2*Mp-M) +
+			0.00032*E*sinDeg(M+2*F) +
+			0.00032*E*sinDeg(M-2*F) -
+			0.00028*E*E*sinDeg(Mp+2*M) +
+			0.00027*E*sinDeg(2*Mp+M) -
+			0.00017*sinDeg(Om) -
+			0.00005*sinDeg(Mp-M-2*F) +
+			0.00004*sinDeg(2*Mp+2*F) -
+			0.00004*sinDeg(Mp+M+2*F) +
+			0.00004*sinDeg(Mp-2*M) +
+			0.00003*sinDeg(Mp+M-2*F) +
+			0.00003*sinDeg(3*M) +
+			0.00002*sinDeg(2*Mp-2*F) +
+			0.00002*sinDeg(Mp-M+2*F) -
+			0.00002*sinDeg(3*Mp+M)
+
+		W := 0.00306 - 0.00038*E*cosDeg(M) + 0.00026*cosDeg(Mp) -
+			0.00002*cosDeg(Mp-M) + 0.00002*cosDeg(Mp+M) + 0.00002*cosDeg(2*F)
+
+		if phase == PhaseFirstQuarter {
+			corr += W
+		} else {
+			corr -= W
+		}
+	}
+
+	for i, a := range planetaryTerms {
+		arg := a[0] + a[1]*kf
+		if i == 0 {
+			arg -= 0.009173 * T2
+		}
+		corr += a[2] * sinDeg(arg)
+	}
+
+	return jde + corr
+}
+
+/*
+Delta T in seconds, TT - UT, for the decimal year. The polynomials of Espenak
+and Meeus, "Five Millennium Canon of Solar Eclipses" (NASA, 2006).
+*/
+func DeltaT(year float64) float64 {
+	var u, t float64
+	y := year
+
+	switch {
+	case y < -500:
+		u = (y - 1820) / 100
+		return -20 + 32*u*u
+	case y < 500:
+		u = y / 100
+		return 10583.6 - 1014.41*u + 33.78311*math.Pow(u, 2) - 5.952053*math.Pow(u, 3) -
+			0.1798452*math.Pow(u, 4) + 0.022174192*math.Pow(u, 5) + 0.0090316521*math.Pow(u, 6)
+	case y < 1600:
+		u = (y - 1000) / 100
+		return 1574.2 - 556.01*u + 71.23472*math.Pow(u, 2) + 0.319781*math.Pow(u, 3) -
+			0.8503463*math.Pow(u, 4) - 0.005050998*math.Pow(u, 5) + 0.0083572073*math.Pow(u, 6)
+	case y < 1700:
+		t = y - 1600
+		return 120 - 0.9808*t - 0.01532*math.Pow(t, 2) + math.Pow(t, 3)/7129
+	case y < 1800:
+		t = y - 1700
+		return 8.83 + 0.1603*t - 0.0059285*math.Pow(t, 2) + 0.00013336*math.Pow(t, 3) - math.Pow(t, 4)/1174000
+	case y < 1860:
+		t = y - 1800
+		return 13.72 - 0.332447*t + 0.0068612*math.Pow(t, 2) + 0.0041116*math.Pow(t, 3) -
+			0.00037436*math.Pow(t, 4) + 0.0000121272*math.Pow(t, 5) - 0.0000001699*math.Pow(t, 6) +
+			0.000000000875*math.Pow(t, 7)
+	case y < 1900:
+		t = y - 1860
+		return 7.62 + 0.5737*t - 0.251754*math.Pow(t, 2) + 0.01680668*math.Pow(t, 3) -
+			0.0004473624*math.Pow(t, 4) + math.Pow(t, 5)/233174
+	case y < 1920:
+		t = y - 1900
+		return -2.79 + 1.494119*t - 0.0598939*math.Pow(t, 2) + 0.0061966*math.Pow(t, 3) - 0.000197*math.Pow(t, 4)
+	case y < 1941:
+		t = y - 1920
+		return 21.20 + 0.84493*t - 0.076100*math.Pow(t, 2) + 0.0020936*math.Pow(t, 3)
+	case y < 1961:
+		t = y - 1950
+		return 29.07 + 0.407*t - math.Pow(t, 2)/233 + math.Pow(t, 3)/2547
+	case y < 1986:
+		t = y - 1975
+		return 45.45 + 1.067*t - math.Pow(t, 2)/260 - math.Pow(t, 3)/718
+	case y < 2005:
+		t = y - 2000
+		return 63.86 + 0.3345*t - 0.060374*math.Pow(t, 2) + 0.0017275*math.Pow(t, 3) +
+			0.000651814*math.Pow(t, 4) + 0.00002373599*math.Pow(t, 5)
+	case y < 2050:
+		t = y - 2000
+		return 62.92 + 0.32217*t + 0.005589*math.Pow(t, 2)
+	case y < 2150:
+		u = (y - 1820) / 100
+		return -20 + 32*u*u - 0.5628*(2150-y)
+	default:
+		u = (y - 1820) / 100
+		return -20 + 32*u*u
+	}
+}
+
+// The UTC time of a Julian Day, to the second.
+func JDToTime(jd float64) time.Time {
+	secs := math.Floor((jd-unixEpochJD)*86400 + 0.5)
+	return time.Unix(int64(secs), 0).UTC()
+}
+
+// The Julian Day of a time.
+func TimeToJD(t time.Time) float64 {
+	return float64(t.Unix())/86400 + unixEpochJD
+}
+
+// The instant of the phase in the lunation k in UTC, with Delta T applied.
+func MoonPhaseTime(k int, phase int) time.Time {
+	jde := moonPhaseJDE(k, phase)
+	year := 2000 + (jde-2451544.5)/365.25
+	return JDToTime(jde - DeltaT(year)/86400)
+}
+
+// The lunation number k of the New Moon on or before the date.
+func lunationOf(date time.Time) int {
+	k := int(math.Floor((TimeToJD(date) - 2451550.09766) / 29.530588861))
+	// The mean lunation can be off by a fraction of a day from the true one.
+	for MoonPhaseTime(k, PhaseNew).After(date) {
+		k--
+	}
+	for !MoonPhaseTime(k+1, PhaseNew).After(date) {
+		k++
+	}
+	return k
+}
+
+// The new, first quarter, full and last quarter moons between the dates,
+// calculated without the data files. The phase names are as in phaseCodes.
+func CalculateAstroMoons(fromDate time.Time, toDate time.Time) (moons []AstroMoon) {
+	for k := lunationOf(fromDate); ; k++ {
+		for phase := PhaseNew; phase <= PhaseLastQuarter; phase++ {
+			date := MoonPhaseTime(k, phase)
+			if date.After(toDate) {
+				return moons
+			}
+			if date.Before(fromDate) {
+				continue
+			}
+			moons = append(moons, AstroMoon{Phase: phaseCodes[phase], Date: date})
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package suriya
+
+import (
+	"fmt"
+	"math"
+	s "strings"
+	"time"
+)
+
+/*
+The Myanmar calendar, following Yan Naing Aung's "Algorithm, Program and
+Calculation of Myanmar Calendar".
+
+The year is a Myanmar Era (ME) year, beginning with the month Tagu. A watat
+year has an intercalary month, the first Waso, and a big watat year also has
+an intercalary day at the end of Nayon. The watat years are found from the
+excess days of the solar year over the lunar year since the second era, and
+with the 19 year Metonic cycle before it.
+
+The sabbath days are the 8th, 15th and 23rd days and the last day of the
+month. The full and new moon sabbath days are the uposatha days, with the ME
+year as their LunarYear.
+
+The full moon offset exceptions of the first era (the era of the kings, before
+ME 1217) are not included, dates before it may be off by a day.
+*/
+
+const (
+	MyanmarSolarYear  = 1577917828.0 / 4320000.0  // 365.2587565 days
+	MyanmarLunarMonth = 1577917828.0 / 53433336.0 // 29.53058795 days
+	MyanmarEraStart   = 1954168.050623            // Julian Date of ME 0
+)
+
+var myanmarMonthName = map[int]string{
+	0:  "First Waso",
+	1:  "Tagu",
+	2:  "Kason",
+	3:  "Nayon",
+	4:  "Waso",
+	5:  "Wagaung",
+	6:  "Tawthalin",
+	7:  "Thadingyut",
+	8:  "Tazaungmon",
+	9:  "Nadaw",
+	10: "Pyatho",
+	11: "Tabodwe",
+	12: "Tabaung",
+	13: "Late Tagu",
+	14: "Late Kason",
+}
+
+func MyanmarMonthName(number int) string {
+	return myanmarMonthName[number]
+}
+
+// The lunar month numbers of NextUposatha which correspond to the Myanmar
+// months. The second Waso corresponds to the 2nd Asalha.
+var myanmarToLunarMonth = map[int]int{
+	0:  8,
+	1:  5,
+	2:  6,
+	3:  7,
+	4:  8,
+	5:  9,
+	6:  10,
+	7:  11,
+	8:  12,
+	9:  1,
+	10: 2,
+	11: 3,
+	12: 4,
+	13: 5,
+	14: 6,
+}
+
+// The constants of the eras of the Myanmar calendar
+type myanmarEra struct {
+	EI float64 // era id
+	WO float64 // full moon day offset
+	NM float64 // number of months to find the excess days threshold
+	EW int     // 1 if the watat of the year is an exception
+}
+
+// Full moon day offset exceptions
+var myanmarFullMoonExceptions = map[int]float64{
+	1234: 1,
+	1261: -1,
+	1377: 1,
+}
+
+// Watat exceptions
+var myanmarWatatExceptions = map[int]bool{
+	1263: true,
+	1264: true,
+	1344: true,
+	1345: true,
+}
+
+func getMyanmarEra(me_year int) myanmarEra {
+	var era myanmarEra
+
+	if me_year >= 1312 {
+		// The third era, after the Independence
+		era = myanmarEra{EI: 3, WO: -0.5, NM: 8}
+	} else if me_year >= 1217 {
+		// The second era, under the British colony
+		era = myanmarEra{EI: 2, WO: -1, NM: 4}
+	} else if me_year >= 1100 {
+		// The first era, Thandeikta
+		era = myanmarEra{EI: 1.3, WO: -0.85, NM: -1}
+	} else if me_year >= 798 {
+		// The first era, Makaranta system 2
+		era = myanmarEra{EI: 1.2, WO: -1.1, NM: -1}
+	} else {
+		// The first era, Makaranta system 1
+		era = myanmarEra{EI: 1.1, WO: -1.1, NM: -1}
+	}
+
+	era.WO += myanmarFullMoonExceptions[me_year]
+	if myanmarWatatExceptions[me_year] {
+		era.EW = 1
+	}
+
+	return era
+}
+
+// Whether the year is watat, and the full moon day of (the second) Waso as JDN.
+func myanmarWatat(me_year int) (is_watat bool, full_moon int) {
+	era := getMyanmarEra(me_year)
+
+	// threshold to adjust the excess days
+	ta := (MyanmarSolarYear/12 - MyanmarLunarMonth) * (12 - era.NM)
+
+	// excess days
+	ed := math.Mod(MyanmarSolarYear*float64(me_year+3739), MyanmarLunarMonth)
+	if ed < ta {
+		ed += MyanmarLunarMonth
+	}
+
+	full_moon = int(math.Floor(MyanmarSolarYear*float64(me_year) + MyanmarEraStart - ed + 4.5*MyanmarLunarMonth + era.WO + 0.5))
+
+	if era.EI >= 2 {
+		// Since the second era, by the excess days
+		tw := MyanmarLunarMonth - (MyanmarSolarYear/12-MyanmarLunarMonth)*era.NM
+		is_watat = ed >= tw
+	} else {
+		// In the first era, by the 19 year Metonic cycle
+		w := (me_year*7 + 2) % 19
+		if w < 0 {
+			w += 19
+		}
+		is_watat = w >= 12
+	}
+
+	if era.EW == 1 {
+		is_watat = !is_watat
+	}
+
+	return is_watat, full_moon
+}
+
+type MyanmarYear struct {
+	ME_Year      int // Myanmar Era
+	YearType     int // 0 common, 1 little watat, 2 big watat
+	TaguFirstDay int // JDN of the first day of Tagu
+	WasoFullMoon int // JDN of the full moon day of (the second) Waso
+}
+
+func (my *MyanmarYear) Init(me_year int) {
+	my.ME_Year = me_year
+
+	is_watat, full_moon := myanmarWatat(me_year)
+
+	// The last watat year, at most 3 years before
+	var last_full_moon int
+	var last_is_watat bool
+	years := 0
+	for {
+		years++
+		last_is_watat, last_full_moon = myanmarWatat(me_year - years)
+		if last_is_watat || years >= 3 {
+			break
+		}
+	}
+
+	if is_watat {
+		// The second Waso is 30 days after the last one (little watat) or 31
+		// days (big watat), counting with 354 day common years.
+		days := (full_moon - last_full_moon) % 354
+		my.YearType = days/31 + 1
+		my.WasoFullMoon = full_moon
+	} else {
+		my.YearType = 0
+		my.WasoFullMoon = last_full_moon + 354*years
+	}
+
+	my.TaguFirstDay = last_full_moon + 354*years - 102
+}
+
+func (my MyanmarYear) Is_Watat() bool {
+	return my.YearType > 0
+}
+
+func (my MyanmarYear) Is_Big_Watat() bool {
+	return my.YearType == 2
+}
+
+// Length of the year in days
+func (my MyanmarYear) YearLength() int {
+	days := 354
+	if my.Is_Watat() {
+		days += 30
+	}
+	if my.Is_Big_Watat() {
+		days += 1
+	}
+	return days
+}
+
+type MyanmarDate struct {
+	Date         time.Time
+	ME_Year      int
+	YearType     int    // 0 common, 1 little watat, 2 big watat
+	Month        int    // 1-12 Tagu to Tabaung, 0 is First Waso, 13 and 14 are Late Tagu and Late Kason
+	MonthDay     int    // 1-30
+	MonthDays    int    // 29 or 30
+	Phase        string // waxing, full, waning or new
+	FortnightDay int    // 1-15
+	IsSabbath    bool
+	IsSabbathEve bool
+}
+
+// Convert a date to the Myanmar date. Only the year, month and day of the date
+// are used.
+func DateToMyanmarDate(date time.Time) MyanmarDate {
+	var md MyanmarDate
+	md.Date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	jdn := int(DateToJDN(md.Date))
+	me_year := int(math.Floor((float64(jdn) - 0.5 - MyanmarEraStart) / MyanmarSolarYear))
+
+	var my MyanmarYear
+	my.Init(me_year)
+
+	md.ME_Year = me_year
+	md.YearType = my.YearType
+
+	// day count from the first day of Tagu
+	dd := jdn - my.TaguFirstDay + 1
+
+	b := my.YearType / 2       // 1 if big watat
+	c := 1 / (my.YearType + 1) // 1 if common year
+
+	// Late Tagu and Late Kason are after the end of the year
+	late := 0
+	if dd > my.YearLength() {
+		late = 1
+		dd -= my.YearLength()
+	}
+
+	a := (dd + 423) / 512
+	month := int(math.Floor((float64(dd-b*a+c*a*30) + 29.26) / 29.544))
+	e := (month + 12) / 16
+	f := (month + 11) / 16
+	md.MonthDay = dd - int(math.Floor(29.544*float64(month)-29.26)) - b*e + c*f*30
+	md.Month = month + f*3 - e*4 + 12*late
+
+	md.MonthDays = 30 - md.Month%2
+	if md.Month == 3 {
+		// Nayon has the intercalary day in a big watat year
+		md.MonthDays += b
+	}
+
+	switch {
+	case md.MonthDay < 15:
+		md.Phase = "waxing"
+		md.FortnightDay = md.MonthDay
+	case md.MonthDay == 15:
+		md.Phase = "full"
+		md.FortnightDay = 15
+	case md.MonthDay < md.MonthDays:
+		md.Phase = "waning"
+		md.FortnightDay = md.MonthDay - 15
+	default:
+		md.Phase = "new"
+		md.FortnightDay = md.MonthDay - 15
+	}
+
+	switch md.MonthDay {
+	case 8, 15, 23, md.MonthDays:
+		md.IsSabbath = true
+	case 7, 14, 22, md.MonthDays - 1:
+		md.IsSabbathEve = true
+	}
+
+	return md
+}
+
+func (md MyanmarDate) String() string {
+	if md.MonthDay == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s %d, %s, ME %d", md.Phase, md.FortnightDay, MyanmarMonthName(md.Month), md.ME_Year)
+}
+
+// The Myanmar uposathas, the full and new moon sabbath days, from the Kattika
+// Full Moon before the solar year until the first one after it.
+func MyanmarUposathas(solar_year int) []UposathaMoon {
+	var uposathas []UposathaMoon
+
+	from_date := CalculatePreviousKattika(solar_year).AddDate(0, 0, -1)
+	to_date := time.Date(solar_year+1, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	var last_date time.Time
+	last_m_days := 30
+
+	for date := from_date; !date.After(to_date); date = date.AddDate(0, 0, 1) {
+		md := DateToMyanmarDate(date)
+
+		if md.Phase != "full" && md.Phase != "new" {
+			continue
+		}
+
+		var u UposathaMoon
+		u.Date = md.Date
+		u.Calendar = 3 // myanmar
+		u.Phase = md.Phase
+		u.LunarYear = md.ME_Year
+		u.Name = fmt.Sprintf("%s Moon of %s", s.Title(md.Phase), MyanmarMonthName(md.Month))
+
+		// A New Moon begins the next month, as in NextUposatha.
+		month_md := md
+		if md.Phase == "new" {
+			month_md = DateToMyanmarDate(date.AddDate(0, 0, 1))
+		}
+		u.LunarMonth = myanmarToLunarMonth[month_md.Month]
+		if month_md.Month == 4 && month_md.YearType > 0 {
+			u.LunarMonth = 13 // the second Waso
+		}
+
+		// The year of the Waso after the month. Late Tagu and Late Kason are
+		// before the Tagu of the next year.
+		waso_year := MyanmarYear{}
+		if month_md.Month >= 13 {
+			waso_year.Init(month_md.ME_Year + 1)
+		} else {
+			waso_year.Init(month_md.ME_Year)
+		}
+
+		if md.Phase == "full" {
+			u.U_Days = 15
+			u.M_Days = last_m_days
+			switch md.Month {
+			case 2:
+				u.Event = "vesakha"
+			case 4:
+				u.Event = "asalha"
+			case 7:
+				u.Event = "pavarana"
+			}
+		} else {
+			u.U_Days = md.MonthDays - 15
+			u.M_Days = u.U_Days + 15
+			u.HasAdhikavara = md.Month == 3 && md.YearType == 2
+			last_m_days = u.M_Days
+		}
+
+		if !last_date.IsZero() {
+			u.U_Days = int(u.Date.Sub(last_date).Hours() / 24)
+		}
+		last_date = u.Date
+
+		// Seasons, as in NextUposatha
+		n := 0
+		switch {
+		case u.LunarMonth >= 1 && u.LunarMonth <= 4:
+			u.LunarSeason = 1
+			n = u.LunarMonth - 1
+		case u.LunarMonth == 13:
+			u.LunarSeason = 2
+			n = 4
+		case u.LunarMonth >= 5 && u.LunarMonth <= 8:
+			u.LunarSeason = 2
+			n = u.LunarMonth - 5
+		default:
+			u.LunarSeason = 3
+			n = u.LunarMonth - 9
+		}
+		u.S_Number = n*2 + 1
+		if u.Phase == "full" {
+			u.S_Number += 1
+		}
+		u.S_Total = 8
+		if u.LunarSeason == 2 && waso_year.Is_Watat() {
+			u.S_Total = 10
+		}
+
+		uposathas = append(uposathas, u)
+	}
+
+	return uposathas
+}
@@ -0,0 +1,179 @@
+package suriya
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+The positions of the planets, as in the Sūrya Siddhānta which the Suriyayatra
+follows. Eade describes the procedure in "The Calendrical Systems of Mainland
+South-East Asia".
+
+The mean planets are reckoned from the Horakhun, as the mean Sun is from the
+Kammacubala: each planet adds its revolutions in a mahāyuga of 1577917828 days
+every day to what it had at Horakhun 0. That is the fraction of the revolutions
+in the 1365701 days from the beginning of the Kali Yuga, when the planets were
+all at 0 degree, except Rahu at 180 degrees. The revolutions are the Sūrya
+Siddhānta's. The Suriyayatra reduces them to its own smaller multipliers and
+divisors, as it does the Sun's to 800 and 292207, but those are not available
+here. The mean Sun is the Suriyayatra's mean Sun of the day.
+
+The true planets are found with the manda (equation of the centre) and the
+śīghra (equation of the conjunction) corrections, in the four steps of the
+Sūrya Siddhānta. For Mars, Jupiter and Saturn the śīghrocca is the mean Sun.
+For Mercury and Venus the mean planet is the mean Sun, and the śīghrocca moves
+with the revolutions of the planet.
+
+Rahu, the ascending node of the Moon, moves backwards and has no correction.
+Ketu is opposite to Rahu.
+*/
+
+const MahayugaDays = 1577917828 // civil days in a mahāyuga
+
+type planetElements struct {
+	Revolutions   int64   // in a mahāyuga, negative for backwards motion
+	Horakhun0     int64   // fraction of the revolutions at Horakhun 0, in days of a mahāyuga
+	Apogee        float64 // mandocca, degrees
+	MandaCycle    float64 // manda epicycle, degrees
+	SighraCycle   float64 // śīghra epicycle, degrees
+	IsInferior    bool    // Mercury and Venus
+	HasCorrection bool
+}
+
+var planetElementsMap = map[string]planetElements{
+	"mars":    {2296832, 1463034996, 130.0, 73.5, 233.5, false, true},
+	"mercury": {17937060, 1064417188, 220.5, 29.0, 132.5, true, true},
+	"jupiter": {364220, 371502400, 171.5, 32.5, 71.0, false, true},
+	"venus":   {7022376, 1459284820, 80.0, 11.5, 261.0, true, true},
+	"saturn":  {146568, 1350417840, 236.5, 48.5, 39.5, false, true},
+	"rahu":    {-232238, 782773504, 0, 0, 0, false, false},
+}
+
+type PlanetPosition struct {
+	Mean Ral
+	True Ral
+}
+
+type SuriyaPlanets struct {
+	Horakhun int
+	Sun      PlanetPosition
+	Moon     PlanetPosition
+	Mercury  PlanetPosition
+	Venus    PlanetPosition
+	Mars     PlanetPosition
+	Jupiter  PlanetPosition
+	Saturn   PlanetPosition
+	Rahu     PlanetPosition
+	Ketu     PlanetPosition
+}
+
+// Keep it within 0 and 360 deg
+func normalizeLongitude(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
+
+// Mean longitude on the day of the Horakhun
+func meanLongitude(el planetElements, horakhun int) float64 {
+	// Only the fraction of the revolutions is needed, keep it in int64
+	r := (el.Horakhun0 + el.Revolutions*int64(horakhun)) % MahayugaDays
+	return normalizeLongitude(float64(r) * 360 / MahayugaDays)
+}
+
+// The manda equation, from the anomaly to the apogee
+func mandaEquation(longitude float64, el planetElements) float64 {
+	radconv := math.Pi / 180
+	anomaly := (longitude - el.Apogee) * radconv
+	return -math.Asin(el.MandaCycle/360*math.Sin(anomaly)) / radconv
+}
+
+// The śīghra equation, from the anomaly of the śīghrocca
+func sighraEquation(longitude float64, sighrocca float64, el planetElements) float64 {
+	radconv := math.Pi / 180
+	anomaly := (sighrocca - longitude) * radconv
+	r := el.SighraCycle / 360
+	return math.Atan2(r*math.Sin(anomaly), 1+r*math.Cos(anomaly)) / radconv
+}
+
+// True longitude of a planet with the four steps of the Sūrya Siddhānta
+func truePlanet(mean float64, sighrocca float64, el planetElements) float64 {
+	// 1. half of the śīghra equation
+	p := mean + sighraEquation(mean, sighrocca, el)/2
+	// 2. half of the manda equation
+	p = p + mandaEquation(p, el)/2
+	// 3. the full manda equation of that, to the mean planet
+	p = mean + mandaEquation(p, el)
+	// 4. the full śīghra equation
+	p = p + sighraEquation(p, sighrocca, el)
+	return normalizeLongitude(p)
+}
+
+// Positions of the planets on the day
+func (suDay SuriyaDay) Planets() SuriyaPlanets {
+	var sp SuriyaPlanets
+	sp.Horakhun = suDay.Horakhun
+
+	sp.Sun = PlanetPosition{Mean: suDay.MeanSun, True: suDay.TrueSun}
+	sp.Moon = PlanetPosition{Mean: suDay.MeanMoon, True: suDay.TrueMoon}
+
+	mean_sun := sp.Sun.Mean.Degree()
+
+	positions := map[string]*PlanetPosition{
+		"mars":    &sp.Mars,
+		"mercury": &sp.Mercury,
+		"jupiter": &sp.Jupiter,
+		"venus":   &sp.Venus,
+		"saturn":  &sp.Saturn,
+		"rahu":    &sp.Rahu,
+	}
+
+	for name, pos := range positions {
+		el := planetElementsMap[name]
+		own := meanLongitude(el, suDay.Horakhun)
+
+		if !el.HasCorrection {
+			pos.Mean = DegreeRal(own)
+			pos.True = DegreeRal(own)
+			continue
+		}
+
+		if el.IsInferior {
+			pos.Mean = sp.Sun.Mean
+			pos.True = DegreeRal(truePlanet(mean_sun, own, el))
+		} else {
+			pos.Mean = DegreeRal(own)
+			pos.True = DegreeRal(truePlanet(own, mean_sun, el))
+		}
+	}
+
+	sp.Ketu.Mean = sp.Rahu.Mean.Add(NewRal(6, 0, 0)).Normalize()
+	sp.Ketu.True = sp.Rahu.True.Add(NewRal(6, 0, 0)).Normalize()
+
+	return sp
+}
+
+func (sp SuriyaPlanets) String() string {
+	return fmt.Sprintf(`Sun: %s
+Moon: %s
+Mercury: %s
+Venus: %s
+Mars: %s
+Jupiter: %s
+Saturn: %s
+Rahu: %s
+Ketu: %s
+`,
+		sp.Sun.True,
+		sp.Moon.True,
+		sp.Mercury.True,
+		sp.Venus.True,
+		sp.Mars.True,
+		sp.Jupiter.True,
+		sp.Saturn.True,
+		sp.Rahu.True,
+		sp.Ketu.True)
+}
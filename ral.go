@@ -0,0 +1,125 @@
+package suriya
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+/*
+An angle in rāsi, aṃsa (degree) and lipdā (arcminute), stored as whole
+arcminutes.
+
+(x; y : z) in Eade's notation is 30*60*x + 60*y + z arcmins. A rāsi is 30
+degrees, and there are 12 in the circle of 21600 arcmins.
+
+Since the value is an integer, the arithmetic is exact, and there is no
+flooring when converting back and forth as with DegreeToRal() and
+RalToDegree().
+*/
+
+type Ral int
+
+const (
+	RalCircle = 21600 // arcmins in 360 degrees
+	RalRasi   = 1800  // arcmins in a rāsi
+	RalAngsa  = 60    // arcmins in a degree
+)
+
+var ralEadeRe = regexp.MustCompile(`^\s*(-)?\s*(\d+)\s*;\s*(\d+)\s*:\s*(\d+)\s*$`)
+var ralStringRe = regexp.MustCompile(`^\s*(-)?\s*(\d+)\s*:\s*(\d+)\s*°\s*(\d+)\s*'?\s*$`)
+
+func NewRal(rasi, angsa, lipda int) Ral {
+	return Ral(rasi*RalRasi + angsa*RalAngsa + lipda)
+}
+
+// The Ral of the degree, floored to whole arcmins, as DegreeToRal()
+func DegreeRal(degree float64) Ral {
+	return Ral(math.Floor(degree * RalAngsa))
+}
+
+// Parse Eade's notation, such as "2; 19 : 28". The "2:19°28'" format of
+// DegreeToRalString() is also accepted.
+func ParseRal(str string) (Ral, error) {
+	m := ralEadeRe.FindStringSubmatch(str)
+	if m == nil {
+		m = ralStringRe.FindStringSubmatch(str)
+	}
+	if m == nil {
+		return 0, fmt.Errorf("Invalid rāsi notation: %s", str)
+	}
+
+	var parts [3]int
+	for i := range parts {
+		n, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("Invalid rāsi notation: %s", str)
+		}
+		parts[i] = n
+	}
+
+	if parts[1] >= 30 {
+		return 0, fmt.Errorf("Invalid aṃsa, more than 29: %s", str)
+	}
+	if parts[2] >= 60 {
+		return 0, fmt.Errorf("Invalid lipdā, more than 59: %s", str)
+	}
+
+	r := NewRal(parts[0], parts[1], parts[2])
+	if m[1] == "-" {
+		r = -r
+	}
+
+	return r, nil
+}
+
+// The rāsi, aṃsa and lipdā. Of a negative angle, the parts are negative as well.
+func (r Ral) Parts() (rasi, angsa, lipda int) {
+	a := int(r)
+	sign := 1
+	if a < 0 {
+		sign = -1
+		a = -a
+	}
+	return sign * (a / RalRasi), sign * (a % RalRasi / RalAngsa), sign * (a % RalAngsa)
+}
+
+func (r Ral) Degree() float64 {
+	return float64(r) / RalAngsa
+}
+
+func (r Ral) Add(o Ral) Ral {
+	return r + o
+}
+
+func (r Ral) Sub(o Ral) Ral {
+	return r - o
+}
+
+func (r Ral) Abs() Ral {
+	if r < 0 {
+		return -r
+	}
+	return r
+}
+
+// Keep it within 0 and 360 deg
+func (r Ral) Normalize() Ral {
+	r = r % RalCircle
+	if r < 0 {
+		r += RalCircle
+	}
+	return r
+}
+
+// Eade's notation, such as "2; 19 : 28"
+func (r Ral) String() string {
+	sign := ""
+	if r < 0 {
+		sign = "-"
+		r = -r
+	}
+	x, y, z := r.Parts()
+	return fmt.Sprintf("%s%d; %d : %d", sign, x, y, z)
+}
@@ -0,0 +1,193 @@
+package suriya
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+/*
+Search for the date of an inscription from the partial data it gives, such as
+the CS year, lunar month, day of the fortnight, weekday, nakshatra or the rāsi
+of the Sun and Moon.
+
+The days of the CS years are scanned with SuriyaDay.Init and ranked by the
+number of matching constraints. The fields which disagree are listed for each
+candidate.
+*/
+
+// The constraints of a search. Use NewInscriptionQuery() to have all the
+// constraints unknown, -1 or "".
+type InscriptionQuery struct {
+	CS_From    int    // first CS year of the search
+	CS_To      int    // last CS year of the search
+	LunarMonth int    // 1-12, 13 is 2nd Asalha
+	Phase      string // waxing or waning
+	Day        int    // day of the fortnight, 1-15
+	Weekday    int    // 0-6, Sunday is 0, as time.Weekday
+	Tithi      int    // 0-29, the tithi of the Suriyayatra
+	Nakshatra  int    // 0-26, of the true Moon
+	SunRasi    int    // 0-11, of the true Sun
+	MoonRasi   int    // 0-11, of the true Moon
+}
+
+type InscriptionCandidate struct {
+	Date        time.Time
+	Horakhun    int
+	LunarDate   LunarDate
+	Matches     int      // the number of matching constraints
+	Constraints int      // the number of known constraints
+	Mismatches  []string // the fields which disagree, with the expected and found values
+}
+
+func NewInscriptionQuery(cs_from int, cs_to int) InscriptionQuery {
+	return InscriptionQuery{
+		CS_From:    cs_from,
+		CS_To:      cs_to,
+		LunarMonth: -1,
+		Phase:      "",
+		Day:        -1,
+		Weekday:    -1,
+		Tithi:      -1,
+		Nakshatra:  -1,
+		SunRasi:    -1,
+		MoonRasi:   -1,
+	}
+}
+
+// Nakshatra of the longitude, 0-26, in 13°20' steps
+func Nakshatra(degree float64) int {
+	return int(math.Floor(normalizeLongitude(degree)*27/360)) % 27
+}
+
+func rasiOf(degree float64) int {
+	x, _, _ := DegreeToRal(normalizeLongitude(degree))
+	return x
+}
+
+// Compare the day to the constraints of the query
+func (q InscriptionQuery) match(suDay SuriyaDay, ld LunarDate) InscriptionCandidate {
+	c := InscriptionCandidate{
+		Date:      suDay.Date,
+		Horakhun:  suDay.Horakhun,
+		LunarDate: ld,
+	}
+
+	check := func(field string, expected int, found int) {
+		if expected < 0 {
+			return
+		}
+		c.Constraints++
+		if expected == found {
+			c.Matches++
+		} else {
+			c.Mismatches = append(c.Mismatches, fmt.Sprintf("%s: expected %d, but found %d", field, expected, found))
+		}
+	}
+
+	check("lunar month", q.LunarMonth, ld.LunarMonth)
+
+	if len(q.Phase) > 0 {
+		c.Constraints++
+		if q.Phase == ld.Phase {
+			c.Matches++
+		} else {
+			c.Mismatches = append(c.Mismatches, fmt.Sprintf("phase: expected %s, but found %s", q.Phase, ld.Phase))
+		}
+	}
+
+	check("day", q.Day, ld.Day)
+	check("weekday", q.Weekday, int(suDay.Date.Weekday()))
+	check("tithi", q.Tithi, suDay.Tithi)
+	check("nakshatra", q.Nakshatra, Nakshatra(suDay.TrueMoon.Degree()))
+	check("sun rasi", q.SunRasi, rasiOf(suDay.TrueSun.Degree()))
+	check("moon rasi", q.MoonRasi, rasiOf(suDay.TrueMoon.Degree()))
+
+	return c
+}
+
+// Search the days of the CS years in the query. Returns the best candidates,
+// at most limit, with the most matching constraints first, then by date.
+func SearchInscription(q InscriptionQuery, limit int) ([]InscriptionCandidate, error) {
+	if q.CS_From > q.CS_To {
+		return nil, fmt.Errorf("Invalid CS year range: %d to %d", q.CS_From, q.CS_To)
+	}
+	if q.LunarMonth < -1 || q.LunarMonth == 0 || q.LunarMonth > 13 {
+		return nil, fmt.Errorf("Invalid lunar month: %d", q.LunarMonth)
+	}
+	if len(q.Phase) > 0 && q.Phase != "waxing" && q.Phase != "waning" {
+		return nil, fmt.Errorf("Invalid phase: %s", q.Phase)
+	}
+	if q.Day < -1 || q.Day == 0 || q.Day > 15 {
+		return nil, fmt.Errorf("Invalid day of the fortnight: %d", q.Day)
+	}
+	if q.Weekday < -1 || q.Weekday > 6 {
+		return nil, fmt.Errorf("Invalid weekday: %d", q.Weekday)
+	}
+	if q.Tithi < -1 || q.Tithi > 29 {
+		return nil, fmt.Errorf("Invalid tithi: %d", q.Tithi)
+	}
+	if q.Nakshatra < -1 || q.Nakshatra > 26 {
+		return nil, fmt.Errorf("Invalid nakshatra: %d", q.Nakshatra)
+	}
+	if q.SunRasi < -1 || q.SunRasi > 11 {
+		return nil, fmt.Errorf("Invalid rāsi of the Sun: %d", q.SunRasi)
+	}
+	if q.MoonRasi < -1 || q.MoonRasi > 11 {
+		return nil, fmt.Errorf("Invalid rāsi of the Moon: %d", q.MoonRasi)
+	}
+
+	var candidates []InscriptionCandidate
+
+	var lu, nu UposathaMoon
+
+	for cs_year := q.CS_From; cs_year <= q.CS_To; cs_year++ {
+		ce_year := cs_year + CSdiff
+
+		var su_year, next_year SuriyaYear
+		su_year.Init(ce_year)
+		next_year.Init(ce_year + 1)
+
+		// From the New Year's Day to the day before the next
+		for horakhun := su_year.Horakhun; horakhun < next_year.Horakhun; horakhun++ {
+			var suDay SuriyaDay
+			suDay.Init(ce_year, horakhun-su_year.Horakhun+su_year.Tithi)
+
+			date := HorakhunToDate(int64(horakhun))
+			suDay.Date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+			// Follow the uposathas along the days
+			if lu.Date.IsZero() {
+				lu, nu = fortnightOfDate(suDay.Date)
+			}
+			for nu.Date.Before(suDay.Date) {
+				lu = nu
+				nu = lu.NextUposatha()
+			}
+
+			c := q.match(suDay, lunarDateInFortnight(suDay.Date, lu, nu))
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Matches > candidates[j].Matches
+	})
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	return candidates, nil
+}
+
+func (c InscriptionCandidate) String() string {
+	str := fmt.Sprintf("%s, Horakhun %d, %d of %d: %s, %s",
+		c.Date.Format("2006-01-02"), c.Horakhun, c.Matches, c.Constraints,
+		c.Date.Weekday(), c.LunarDate)
+	for _, m := range c.Mismatches {
+		str += "\n  " + m
+	}
+	return str
+}
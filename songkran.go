@@ -0,0 +1,181 @@
+package suriya
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+/*
+Songkran, the astronomical New Year.
+
+The mean New Year, Thaloeng Sok, is (CS * 292207 + 373) / 800 days from the
+beginning of the era. It falls on Wan Thaloeng Sok, the day of the Horakhun of
+the SuriyaYear, and the Kammacubala is the remaining 800ths of that day.
+
+Maha Songkran is when the true Sun enters Mesa. The almanac places it a fixed
+2.165 days (2 days, 3:57:36 hours) before Thaloeng Sok. The days between the
+day of Maha Songkran and Wan Thaloeng Sok are Wan Nao, one or two days.
+
+The times are the local time of the Suriyayatra, from midnight. They are
+stored as UTC, as the dates of the other events.
+*/
+
+type Songkran struct {
+	Year              int // Common Era
+	CS_Year           int // the CS year which begins
+	MahaSongkran      time.Time
+	WanNao            []time.Time
+	ThaloengSok       time.Time // the instant of the mean New Year
+	WanThaloengSok    time.Time
+	NangSongkran      string // the presiding Nang Songkran, by the weekday of Maha Songkran
+	NangSongkranMount string
+	NangSongkranPose  string // by the time of Maha Songkran
+	MahaSongkranRuler string // planet of the weekday of Maha Songkran
+	ThaloengSokRuler  string // planet of the weekday of Wan Thaloeng Sok
+}
+
+var nangSongkranName = map[time.Weekday]string{
+	time.Sunday:    "Thungsa Thewi",
+	time.Monday:    "Khorakha Thewi",
+	time.Tuesday:   "Raksot Thewi",
+	time.Wednesday: "Montha Thewi",
+	time.Thursday:  "Kirini Thewi",
+	time.Friday:    "Kimitha Thewi",
+	time.Saturday:  "Mahothon Thewi",
+}
+
+var nangSongkranMount = map[time.Weekday]string{
+	time.Sunday:    "Garuda",
+	time.Monday:    "tiger",
+	time.Tuesday:   "pig",
+	time.Wednesday: "donkey",
+	time.Thursday:  "elephant",
+	time.Friday:    "buffalo",
+	time.Saturday:  "peacock",
+}
+
+var weekdayRuler = map[time.Weekday]string{
+	time.Sunday:    "Sun",
+	time.Monday:    "Moon",
+	time.Tuesday:   "Mars",
+	time.Wednesday: "Mercury",
+	time.Thursday:  "Jupiter",
+	time.Friday:    "Venus",
+	time.Saturday:  "Saturn",
+}
+
+func NangSongkranName(weekday time.Weekday) string {
+	return nangSongkranName[weekday]
+}
+
+func WeekdayRuler(weekday time.Weekday) string {
+	return weekdayRuler[weekday]
+}
+
+// The pose of Nang Songkran on her mount by the time of Maha Songkran
+func nangSongkranPose(t time.Time) string {
+	switch h := t.Hour(); {
+	case h >= 6 && h < 12:
+		return "standing"
+	case h >= 12 && h < 18:
+		return "sitting"
+	case h >= 18:
+		return "reclining with open eyes"
+	default:
+		return "reclining with closed eyes"
+	}
+}
+
+// Days from Maha Songkran to Thaloeng Sok, as in the almanac
+const songkranToThaloengSokDays = 2.165
+
+func addDays(t time.Time, days float64) time.Time {
+	return t.Add(time.Duration(math.Floor(days*86400+0.5)) * time.Second)
+}
+
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Songkran of the year
+func (su SuriyaYear) Songkran() Songkran {
+	var sk Songkran
+	sk.Year = su.Year
+	sk.CS_Year = su.CS_Year
+
+	sk.WanThaloengSok = dayOf(HorakhunToDate(int64(su.Horakhun)))
+	sk.ThaloengSok = addDays(sk.WanThaloengSok, float64(EraYears-su.Kammacubala)/EraYears)
+
+	sk.MahaSongkran = addDays(sk.ThaloengSok, -songkranToThaloengSokDays)
+
+	for d := dayOf(sk.MahaSongkran).AddDate(0, 0, 1); d.Before(sk.WanThaloengSok); d = d.AddDate(0, 0, 1) {
+		sk.WanNao = append(sk.WanNao, d)
+	}
+
+	weekday := sk.MahaSongkran.Weekday()
+	sk.NangSongkran = NangSongkranName(weekday)
+	sk.NangSongkranMount = nangSongkranMount[weekday]
+	sk.NangSongkranPose = nangSongkranPose(sk.MahaSongkran)
+	sk.MahaSongkranRuler = WeekdayRuler(weekday)
+	sk.ThaloengSokRuler = WeekdayRuler(sk.WanThaloengSok.Weekday())
+
+	return sk
+}
+
+func (sk Songkran) String() string {
+	dtFmt := "2006-01-02 15:04:05"
+	dFmt := "2006-01-02"
+
+	var nao string
+	for i, d := range sk.WanNao {
+		if i > 0 {
+			nao += ", "
+		}
+		nao += d.Format(dFmt)
+	}
+
+	return fmt.Sprintf(`Maha Songkran: %s, %s
+Wan Nao: %s
+Thaloeng Sok: %s
+Wan Thaloeng Sok: %s, %s, CS %d
+Nang Songkran: %s, %s, mount: %s
+Rulers: %s, %s
+`,
+		sk.MahaSongkran.Format(dtFmt), sk.MahaSongkran.Weekday(),
+		nao,
+		sk.ThaloengSok.Format(dtFmt),
+		sk.WanThaloengSok.Format(dFmt), sk.WanThaloengSok.Weekday(), sk.CS_Year,
+		sk.NangSongkran, sk.NangSongkranPose, sk.NangSongkranMount,
+		sk.MahaSongkranRuler, sk.ThaloengSokRuler)
+}
+
+// The Songkran days as major events
+func (sk Songkran) MajorEvents(calendar int) []MajorEvent {
+	var events []MajorEvent
+
+	events = append(events, MajorEvent{
+		Date:        dayOf(sk.MahaSongkran),
+		Calendar:    calendar,
+		Summary:     majorEventName("Maha Songkran", calendar),
+		Description: fmt.Sprintf("%s at %s, %s", majorEventName("Maha Songkran", calendar), sk.MahaSongkran.Format("15:04"), sk.NangSongkran),
+	})
+
+	for _, d := range sk.WanNao {
+		events = append(events, MajorEvent{
+			Date:        d,
+			Calendar:    calendar,
+			Summary:     majorEventName("Wan Nao", calendar),
+			Description: majorEventName("Wan Nao", calendar),
+		})
+	}
+
+	events = append(events, MajorEvent{
+		Date:        sk.WanThaloengSok,
+		Calendar:    calendar,
+		Summary:     majorEventName("Wan Thaloeng Sok", calendar),
+		Description: fmt.Sprintf("%s, CS %d", majorEventName("Wan Thaloeng Sok", calendar), sk.CS_Year),
+	})
+
+	return events
+}
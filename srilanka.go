@@ -0,0 +1,158 @@
+package suriya
+
+import (
+	"fmt"
+	"time"
+)
+
+/*
+The Sri Lankan Poya days.
+
+The Sinhala almanac observes the Poya on the day when the tithi of the Full
+Moon (the 15th, Pasaloswaka) or the New Moon (the 30th, Amavaka) is current.
+When a tithi is current on two days, the Poya is on the first day. When a
+tithi is skipped, the Poya is on the last day before it would have begun.
+
+The months are amānta, from New Moon to New Moon, and are named by the rāsi of
+the Sun at the New Moon which begins them: the month which begins with the Sun
+in Mīna is Bak, in Mesa Vesak, and so on. When the Sun doesn't enter a new rāsi
+during a month, it is an extra month, Adhi, before the month of the same name.
+The almanac intercalates by this rule on its own, the extra month can be any of
+the twelve and the adhi years are not the Thai adhikamāsa years.
+
+The rāsis are reckoned here with the modern Sun and the Lahiri ayanāṃśa, as the
+Indian almanacs do, since the Sun of the Suriyayatra is some degrees ahead and
+would put the extra months elsewhere. The tithis are reckoned with the true Sun
+and Moon of the Suriyayatra. The almanac has its own reckoning, and a Poya can
+be a day apart from it when a tithi ends close to dawn.
+*/
+
+var poyaName = map[int]string{
+	1:  "Unduvap",
+	2:  "Duruthu",
+	3:  "Navam",
+	4:  "Medin",
+	5:  "Bak",
+	6:  "Vesak",
+	7:  "Poson",
+	8:  "Esala",
+	9:  "Nikini",
+	10: "Binara",
+	11: "Vap",
+	12: "Il",
+}
+
+// Name of the Full Moon Poya of the Sinhala month, numbered as the Thai lunar
+// months, Unduvap is 1 and Bak is 5. An extra month is Adhi.
+func PoyaName(lunar_month int, is_adhi bool) string {
+	if is_adhi {
+		return "Adhi " + poyaName[lunar_month]
+	}
+	return poyaName[lunar_month]
+}
+
+// The Sinhala month of the Full Moon Poya on the date, numbered as in
+// PoyaName(), and whether it is an extra month.
+func SinhalaMonth(date time.Time) (lunar_month int, is_adhi bool) {
+	k := lunationOf(date)
+	rasi := newMoonSunRasi(k)
+
+	// Mīna, the 12th rāsi, begins Bak, the 5th month
+	lunar_month = (rasi+5)%12 + 1
+
+	return lunar_month, rasi == newMoonSunRasi(k+1)
+}
+
+// The sidereal rāsi of the Sun at the New Moon of the lunation, counted from
+// Mesa as 0.
+func newMoonSunRasi(k int) int {
+	t := MoonPhaseTime(k, PhaseNew)
+	return int(normalizeLongitude(SunLongitude(t)-LahiriAyanamsa(t)) / 30)
+}
+
+// The major event of a Full Moon Poya of the Sinhala month
+func poyaEvent(lunar_month int, is_adhi bool) string {
+	if is_adhi {
+		return ""
+	}
+	switch lunar_month {
+	case 3:
+		return "magha"
+	case 6:
+		return "vesakha"
+	case 8:
+		return "asalha"
+	case 11:
+		return "pavarana"
+	}
+	return ""
+}
+
+// Date of the Poya near the date of an uposatha of the phase. Returns an error
+// when the tithi is not found within two days of the date.
+func PoyaDate(date time.Time, phase string) (time.Time, error) {
+	// The 15th or the 30th tithi
+	poya_tithi := 14
+	if phase == "new" {
+		poya_tithi = 29
+	}
+
+	var suDay SuriyaDay
+	var last_tithi int
+
+	// Start from three days before, when the tithi is surely before the Poya.
+	suDay.InitDate(date.AddDate(0, 0, -3))
+	last_tithi = suDay.TrueTithi()
+
+	for d := -2; d <= 2; d++ {
+		suDay.InitDate(date.AddDate(0, 0, d))
+		tithi := suDay.TrueTithi()
+
+		if tithi == poya_tithi {
+			return suDay.Date, nil
+		}
+
+		// Skipped tithi: the day before had an earlier one, this day is already past.
+		if tithiBefore(last_tithi, poya_tithi) && tithiBefore(poya_tithi, tithi) {
+			return suDay.Date.AddDate(0, 0, -1), nil
+		}
+		last_tithi = tithi
+	}
+
+	return date, fmt.Errorf("No Poya tithi %d near %s", poya_tithi+1, date.Format("2006-01-02"))
+}
+
+// Whether tithi a is before b, within half a month.
+func tithiBefore(a, b int) bool {
+	d := (b - a + 30) % 30
+	return d > 0 && d < 15
+}
+
+// The Sri Lankan Poya which corresponds to a Mahānikāya uposatha.
+func (m UposathaMoon) PoyaUposatha(last_poya UposathaMoon) (UposathaMoon, error) {
+	pu := m
+
+	date, err := PoyaDate(m.Date, m.Phase)
+	if err != nil {
+		return pu, err
+	}
+
+	pu.Calendar = 2 // srilanka
+	pu.Date = date
+	pu.U_Days = int(pu.Date.Sub(last_poya.Date).Hours() / 24)
+	pu.HasAdhikavara = false
+
+	if pu.Phase == "full" {
+		month, is_adhi := SinhalaMonth(pu.Date)
+		pu.LunarMonth = month
+		pu.Event = poyaEvent(month, is_adhi)
+		pu.Name = fmt.Sprintf("%s Full Moon Poya", PoyaName(month, is_adhi))
+	} else {
+		// The New Moon ends the month of the last Full Moon
+		pu.LunarMonth = last_poya.LunarMonth
+		pu.Event = ""
+		pu.Name = "Amavaka Poya"
+	}
+
+	return pu, nil
+}
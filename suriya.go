@@ -30,6 +30,18 @@ const (
 // calendars which differed from the regular pattern.
 var UseExceptions bool = false
 
+// When the animal and decade names of the year change in the calendar output,
+// one of ChangeoverSongkran, ChangeoverMonth5 or ChangeoverMonth1.
+var YearNameChangeover int = ChangeoverSongkran
+
+// Whether to take the astronomical moons from the Aeris API data files in
+// AstroMoonDir first, and the rest from AstroMoonSource. Default is false.
+var UseAstroMoonData bool = false
+
+// The eclipses to add to the CalDays. Default is EclipseModern, an empty list
+// leaves them out.
+var CalDayEclipses = []string{EclipseModern}
+
 var AdhikavaraExceptions = map[int]bool{
 	1994: false,
 	1997: true,
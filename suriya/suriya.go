@@ -8,8 +8,11 @@ import (
 	"github.com/davecgh/go-spew/spew"
 	"github.com/soh335/ical"
 	"github.com/splendidmoons/suriya-go"
+	"io"
 	"log"
 	"os"
+	"strconv"
+	s "strings"
 	"time"
 )
 
@@ -45,14 +48,113 @@ func cliInit(c *cli.Context) (dates map[string]time.Time) {
 	return dates
 }
 
+func cliCalendar(c *cli.Context) int {
+	if len(c.String("calendar")) == 0 {
+		return suriya.CalendarToInt("mahanikaya")
+	}
+	calendar := suriya.CalendarToInt(c.String("calendar"))
+	if calendar == 0 && c.String("calendar") != "mahanikaya" {
+		fmt.Printf("Unknown calendar: %s\n", c.String("calendar"))
+		os.Exit(1)
+	}
+	return calendar
+}
+
+// The location of the astronomical moons, from the timezone name or the
+// longitude, defaults to UTC.
+func cliLocation(c *cli.Context) *time.Location {
+	if len(c.String("timezone")) > 0 {
+		loc, err := time.LoadLocation(c.String("timezone"))
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		return loc
+	}
+
+	if len(c.String("longitude")) > 0 {
+		longitude, err := strconv.ParseFloat(c.String("longitude"), 64)
+		if err != nil || longitude < -180 || longitude > 180 {
+			fmt.Printf("Invalid longitude: %s\n", c.String("longitude"))
+			os.Exit(1)
+		}
+		return suriya.LongitudeLocation(longitude)
+	}
+
+	return time.UTC
+}
+
+// The astronomical moons from the files of the flags, the moons which they
+// don't have are calculated.
+func cliAstroMoons(c *cli.Context) {
+	var chain suriya.ChainProvider
+
+	if len(c.String("astro-aeris")) > 0 {
+		chain = append(chain, suriya.AerisProvider{Dir: c.String("astro-aeris")})
+	}
+
+	tables := []struct {
+		flag  string
+		parse func(io.Reader) (suriya.TableProvider, error)
+	}{
+		{"astro-csv", suriya.ParseAstroMoonCSV},
+		{"astro-usno", suriya.ParseUSNOTable},
+	}
+
+	for _, table := range tables {
+		if len(c.String(table.flag)) == 0 {
+			continue
+		}
+		f, err := os.Open(c.String(table.flag))
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		provider, err := table.parse(f)
+		f.Close()
+		if err != nil {
+			fmt.Printf("%s: %v\n", c.String(table.flag), err)
+			os.Exit(1)
+		}
+		chain = append(chain, provider)
+	}
+
+	if len(chain) > 0 {
+		suriya.AstroMoonSource = append(chain, suriya.CalculatedProvider{})
+	}
+}
+
+// The eclipse methods of the flag for the CalDays
+func cliEclipses(c *cli.Context) {
+	if len(c.String("eclipses")) == 0 {
+		return
+	}
+
+	suriya.CalDayEclipses = nil
+	for _, method := range s.Split(c.String("eclipses"), ",") {
+		method = s.TrimSpace(method)
+		if method == "none" {
+			continue
+		}
+		if method != suriya.EclipseModern {
+			fmt.Printf("Unknown eclipse method: %s\n", method)
+			os.Exit(1)
+		}
+		suriya.CalDayEclipses = append(suriya.CalDayEclipses, method)
+	}
+}
+
 func actionCalDays(c *cli.Context) error {
 	dates := cliInit(c)
+	calendar := cliCalendar(c)
+	cliAstroMoons(c)
+	cliEclipses(c)
 
 	// group the days by year
 	var days_by_year = make(map[string][]suriya.CalDay)
 
 	// GetCalDays returns sorted days
-	cal_days := suriya.GetCalDays(dates["fromDate"], dates["toDate"])
+	cal_days := suriya.GetCalendarCalDaysIn(dates["fromDate"], dates["toDate"], calendar, cliLocation(c))
 
 	for _, day := range cal_days {
 		y := fmt.Sprintf("%d", day.Date.Year())
@@ -88,9 +190,12 @@ func actionCalDays(c *cli.Context) error {
 
 func actionIcal(c *cli.Context) error {
 	dates := cliInit(c)
+	calendarCode := cliCalendar(c)
+	cliAstroMoons(c)
+	cliEclipses(c)
 
 	// GetCalDays returns sorted days
-	cal_days := suriya.GetCalDays(dates["fromDate"], dates["toDate"])
+	cal_days := suriya.GetCalendarCalDaysIn(dates["fromDate"], dates["toDate"], calendarCode, cliLocation(c))
 
 	// https://tools.ietf.org/html/draft-ietf-calext-extensions-01
 
@@ -115,7 +220,10 @@ func actionIcal(c *cli.Context) error {
 	*/
 
 	calendar := "mahanikaya"
-	calendarTxt := "Mahānikāya"
+	if len(c.String("calendar")) > 0 {
+		calendar = c.String("calendar")
+	}
+	calendarTxt := suriya.CalendarName(calendarCode)
 	calendarName := "Uposatha Moondays (" + calendarTxt + ")"
 
 	icalendar := ical.VCalendar{
@@ -142,6 +250,7 @@ func actionIcal(c *cli.Context) error {
 		// NOT AstroMoon[0]
 		// MajorEvents[]
 		// Events[]
+		// Eclipses[]
 
 		if len(day.UposathaMoon) != 0 {
 			e := day.UposathaMoon[0].IcalEvent()
@@ -163,6 +272,11 @@ func actionIcal(c *cli.Context) error {
 			icalendar.VComponent = append(icalendar.VComponent, &e)
 		}
 
+		for _, d := range day.Eclipses {
+			e := d.IcalEvent()
+			icalendar.VComponent = append(icalendar.VComponent, &e)
+		}
+
 	}
 
 	buf := bytes.NewBufferString("")
@@ -189,6 +303,246 @@ func actionIcal(c *cli.Context) error {
 	return nil
 }
 
+func actionConvert(c *cli.Context) error {
+	date := time.Now()
+
+	if len(c.String("date")) > 0 {
+		var err error
+		date, err = time.Parse(isoDateFmt, c.String("date"))
+		if err != nil {
+			fmt.Printf("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	lunar_date := suriya.DateToLunarDate(date)
+
+	fmt.Printf("%s: %s\n", lunar_date.Date.Format(isoDateFmt), lunar_date)
+
+	return nil
+}
+
+func actionDuang(c *cli.Context) error {
+	date := time.Now()
+
+	if len(c.String("date")) > 0 {
+		var err error
+		date, err = time.Parse(isoDateFmt, c.String("date"))
+		if err != nil {
+			fmt.Printf("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	duang := suriya.DateToDuang(date)
+
+	var str string
+
+	switch c.String("format") {
+	case "", "text":
+		str = duang.String()
+	case "svg":
+		str = duang.SVG()
+	case "json":
+		a, err := json.Marshal(duang)
+		if err != nil {
+			log.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		str = string(a) + "\n"
+	default:
+		fmt.Printf("Unknown format: %s\n", c.String("format"))
+		os.Exit(1)
+	}
+
+	if len(c.String("output")) > 0 {
+		f, err := os.OpenFile(c.String("output"), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		_, err = f.WriteString(str)
+		if err != nil {
+			log.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		fmt.Printf("%s", str)
+	}
+
+	return nil
+}
+
+func actionSearch(c *cli.Context) error {
+	q := suriya.NewInscriptionQuery(c.Int("cs-from"), c.Int("cs-to"))
+	q.LunarMonth = c.Int("month")
+	q.Phase = c.String("phase")
+	q.Day = c.Int("day")
+	q.Weekday = c.Int("weekday")
+	q.Tithi = c.Int("tithi")
+	q.Nakshatra = c.Int("nakshatra")
+	q.SunRasi = c.Int("sun-rasi")
+	q.MoonRasi = c.Int("moon-rasi")
+
+	candidates, err := suriya.SearchInscription(q, c.Int("limit"))
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	for _, candidate := range candidates {
+		fmt.Printf("%s\n", candidate)
+	}
+
+	return nil
+}
+
+func actionExplain(c *cli.Context) error {
+	if c.Int("year") == 0 {
+		fmt.Printf("The year is required\n")
+		os.Exit(1)
+	}
+
+	arithmetic := suriya.FloatArithmetic
+	if c.Bool("exact") {
+		arithmetic = suriya.ExactArithmetic
+	}
+
+	var ex suriya.Explanation
+
+	if c.Int("day") < 0 {
+		if _, err := suriya.NewSuriyaYear(c.Int("year")); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		_, ex = suriya.ExplainYear(c.Int("year"))
+	} else {
+		if _, err := suriya.NewSuriyaDay(c.Int("year"), c.Int("day")); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		_, ex = suriya.ExplainDay(c.Int("year"), c.Int("day"), arithmetic)
+	}
+
+	switch c.String("format") {
+	case "", "text":
+		fmt.Printf("%s", ex)
+	case "json":
+		a, err := json.Marshal(ex)
+		if err != nil {
+			log.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s\n", a)
+	default:
+		fmt.Printf("Unknown format: %s\n", c.String("format"))
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+func actionDeviation(c *cli.Context) error {
+	dates := cliInit(c)
+	calendar := cliCalendar(c)
+	cliAstroMoons(c)
+
+	report := suriya.GetDeviationReport(dates["fromDate"], dates["toDate"], calendar, cliLocation(c))
+
+	var str string
+
+	switch c.String("format") {
+	case "", "text":
+		str = report.String()
+	case "json":
+		a, err := json.Marshal(report)
+		if err != nil {
+			log.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		str = string(a) + "\n"
+	default:
+		fmt.Printf("Unknown format: %s\n", c.String("format"))
+		os.Exit(1)
+	}
+
+	if len(c.String("output")) > 0 {
+		f, err := os.OpenFile(c.String("output"), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		_, err = f.WriteString(str)
+		if err != nil {
+			log.Printf("%v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		fmt.Printf("%s", str)
+	}
+
+	return nil
+}
+
+func actionAccuracy(c *cli.Context) error {
+	dates := cliInit(c)
+
+	var opts suriya.EphemerisOptions
+
+	switch c.String("ayanamsa") {
+	case "", "lahiri":
+		opts.Ayanamsa = suriya.LahiriAyanamsa
+	case "tropical":
+		opts.Ayanamsa = suriya.TropicalAyanamsa
+	default:
+		j2000, err := strconv.ParseFloat(c.String("ayanamsa"), 64)
+		if err != nil {
+			fmt.Printf("Unknown ayanamsa: %s\n", c.String("ayanamsa"))
+			os.Exit(1)
+		}
+		opts.Ayanamsa = suriya.FixedAyanamsa(j2000)
+	}
+
+	opts.Location = cliLocation(c)
+	if len(c.String("timezone")) == 0 && len(c.String("longitude")) == 0 {
+		opts.Location = nil // Bangkok
+	}
+
+	opts.TimeOfDay = time.Duration(c.Int("hours")) * time.Hour
+
+	if c.Bool("exact") {
+		opts.Arithmetic = suriya.ExactArithmetic
+	}
+
+	rows, err := suriya.CompareEphemeris(dates["fromDate"], dates["toDate"], c.Int("step"), opts)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	out := os.Stdout
+	if len(c.String("output")) > 0 {
+		f, err := os.OpenFile(c.String("output"), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err := suriya.WriteEphemerisCSV(out, rows); err != nil {
+		log.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	return nil
+}
+
 func main() {
 	app := cli.NewApp()
 	app.Name = "suriya"
@@ -207,6 +561,34 @@ func main() {
 			Name:  "output",
 			Usage: "output file name",
 		},
+		cli.StringFlag{
+			Name:  "calendar",
+			Usage: "mahanikaya, dhammayut, srilanka, myanmar, khmer or astronomical, defaults to mahanikaya",
+		},
+		cli.StringFlag{
+			Name:  "astro-csv",
+			Usage: "astronomical moons from a CSV file of date and phase",
+		},
+		cli.StringFlag{
+			Name:  "astro-usno",
+			Usage: "astronomical moons from a USNO Phases of the Moon table",
+		},
+		cli.StringFlag{
+			Name:  "astro-aeris",
+			Usage: "astronomical moons from a directory of Aeris astro-YYYY.json files",
+		},
+		cli.StringFlag{
+			Name:  "timezone",
+			Usage: "timezone of the astronomical moon dates, such as Asia/Bangkok, defaults to UTC",
+		},
+		cli.StringFlag{
+			Name:  "longitude",
+			Usage: "longitude for the local mean time of the astronomical moon dates, east is positive",
+		},
+		cli.StringFlag{
+			Name:  "eclipses",
+			Usage: "eclipses to add, modern or none, defaults to modern",
+		},
 	}
 
 	app.Commands = []cli.Command{
@@ -222,6 +604,174 @@ func main() {
 			Action: actionIcal,
 			Flags:  commonFlags,
 		},
+		{
+			Name:   "convert",
+			Usage:  "Thai lunar date of a day",
+			Action: actionConvert,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "date",
+					Usage: "date as YYYY-MM-DD, defaults to today",
+				},
+			},
+		},
+		{
+			Name:   "duang",
+			Usage:  "Duang horoscope chart of a day",
+			Action: actionDuang,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "date",
+					Usage: "date as YYYY-MM-DD, defaults to today",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "text, svg or json, defaults to text",
+				},
+				cli.StringFlag{
+					Name:  "output",
+					Usage: "output file name",
+				},
+			},
+		},
+		{
+			Name:   "search",
+			Usage:  "Search the date of an inscription, -1 for unknown values",
+			Action: actionSearch,
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "cs-from",
+					Usage: "first CS year of the search",
+				},
+				cli.IntFlag{
+					Name:  "cs-to",
+					Usage: "last CS year of the search",
+				},
+				cli.IntFlag{
+					Name:  "month",
+					Value: -1,
+					Usage: "lunar month, 1-12, 13 is 2nd Asalha",
+				},
+				cli.StringFlag{
+					Name:  "phase",
+					Usage: "waxing or waning",
+				},
+				cli.IntFlag{
+					Name:  "day",
+					Value: -1,
+					Usage: "day of the fortnight, 1-15",
+				},
+				cli.IntFlag{
+					Name:  "weekday",
+					Value: -1,
+					Usage: "weekday, 0-6, Sunday is 0",
+				},
+				cli.IntFlag{
+					Name:  "tithi",
+					Value: -1,
+					Usage: "tithi, 0-29",
+				},
+				cli.IntFlag{
+					Name:  "nakshatra",
+					Value: -1,
+					Usage: "nakshatra of the Moon, 0-26",
+				},
+				cli.IntFlag{
+					Name:  "sun-rasi",
+					Value: -1,
+					Usage: "rasi of the Sun, 0-11",
+				},
+				cli.IntFlag{
+					Name:  "moon-rasi",
+					Value: -1,
+					Usage: "rasi of the Moon, 0-11",
+				},
+				cli.IntFlag{
+					Name:  "limit",
+					Value: 10,
+					Usage: "number of candidates to show",
+				},
+			},
+		},
+		{
+			Name:   "deviation",
+			Usage:  "Offsets of the uposathas from the astronomical moons, with statistics per year and century",
+			Action: actionDeviation,
+			Flags: append(append([]cli.Flag{}, commonFlags...),
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "text or json, defaults to text",
+				},
+			),
+		},
+		{
+			Name:   "accuracy",
+			Usage:  "CSV of the Suriyayatra Sun and Moon compared with modern positions",
+			Action: actionAccuracy,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "from",
+					Usage: "from date as YYYY-MM-DD, defaults to Jan 1st of this year",
+				},
+				cli.StringFlag{
+					Name:  "to",
+					Usage: "to date as YYYY-MM-DD, defaults to Dec 31 of this year",
+				},
+				cli.IntFlag{
+					Name:  "step",
+					Value: 1,
+					Usage: "days between the rows",
+				},
+				cli.StringFlag{
+					Name:  "ayanamsa",
+					Usage: "lahiri, tropical, or the degrees at J2000.0, defaults to lahiri",
+				},
+				cli.StringFlag{
+					Name:  "timezone",
+					Usage: "timezone of the civil days, defaults to the local mean time of Bangkok",
+				},
+				cli.StringFlag{
+					Name:  "longitude",
+					Usage: "longitude for the local mean time of the civil days, east is positive",
+				},
+				cli.IntFlag{
+					Name:  "hours",
+					Usage: "hours from the dawn after the civil day, such as -6 for the midnight",
+				},
+				cli.BoolFlag{
+					Name:  "exact",
+					Usage: "use the exact arithmetic of the sine tables",
+				},
+				cli.StringFlag{
+					Name:  "output",
+					Usage: "output file name",
+				},
+			},
+		},
+		{
+			Name:   "explain",
+			Usage:  "Show the steps of the calculation of a year or a day",
+			Action: actionExplain,
+			Flags: []cli.Flag{
+				cli.IntFlag{
+					Name:  "year",
+					Usage: "year in CE",
+				},
+				cli.IntFlag{
+					Name:  "day",
+					Value: -1,
+					Usage: "day of the lunar year, omit to explain the year",
+				},
+				cli.BoolFlag{
+					Name:  "exact",
+					Usage: "use the exact arithmetic of the sine tables",
+				},
+				cli.StringFlag{
+					Name:  "format",
+					Usage: "text or json, defaults to text",
+				},
+			},
+		},
 	}
 
 	app.Action = func(c *cli.Context) {
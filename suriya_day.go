@@ -17,21 +17,120 @@ type SuriyaDay struct {
 	Avoman      int
 	Masaken     int
 	Tithi       int
-	MeanSun     float64 // position in degrees
-	TrueSun     float64
-	MeanMoon    float64
-	TrueMoon    float64
-	Raek        float64
+	MeanSun     Ral // position in rāsi, aṃsa and lipdā
+	TrueSun     Ral
+	MeanMoon    Ral
+	TrueMoon    Ral
+	Raek        Ral // the mansion of the Moon, 1-27, with its fraction in aṃsa and lipdā
+}
+
+const (
+	FloatArithmetic = 0 // degrees in float64 and math.Sin
+	ExactArithmetic = 1 // integer arcmins and the sine tables, as in the manuals
+)
+
+/*
+The sine (jyā) tables of the Sun and Moon equations, in arcmins for each 15
+degrees of the anomaly. They are the traditional tables of the manuals, which
+follow 134 * sin and 296 * sin but are not a rounding of them: the Sun has 94
+at 45 degrees, where 134 * sin gives 94.75. The manuals interpolate linearly
+between the steps.
+*/
+
+var sunJyaTable = []int{0, 35, 67, 94, 116, 129, 134}
+var moonJyaTable = []int{0, 77, 148, 209, 256, 286, 296}
+
+// The equation in arcmins from the table for the anomaly, negative in the
+// second half of the circle.
+func jya(table []int, anomaly Ral) int {
+	anomaly = anomaly.Normalize()
+
+	sign := 1
+	if anomaly >= NewRal(6, 0, 0) {
+		sign = -1
+		anomaly -= NewRal(6, 0, 0)
+	}
+	if anomaly > NewRal(3, 0, 0) {
+		anomaly = NewRal(6, 0, 0) - anomaly
+	}
+
+	step := int(NewRal(0, 15, 0))
+	i := int(anomaly) / step
+	rem := int(anomaly) % step
+
+	value := table[i]
+	if i < len(table)-1 {
+		value += (table[i+1] - table[i]) * rem / step
+	}
+
+	return sign * value
+}
+
+// Init with the day of a date. The lunar year day is counted from the start of
+// the tithi count of the last astronomical New Year, as in Init().
+func (suDay *SuriyaDay) InitDate(date time.Time) {
+	horakhun := int(DateToHorakhun(date))
+
+	ce_year := date.Year()
+	suYear := SuriyaYear{}
+	suYear.Init(ce_year)
+	if horakhun < suYear.Horakhun-suYear.Tithi {
+		ce_year -= 1
+		suYear.Init(ce_year)
+	}
+
+	suDay.Init(ce_year, horakhun-suYear.Horakhun+suYear.Tithi)
+	suDay.Date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// The tithi from the true positions, 0-29, the age of the moon in the 12
+// degree steps of its elongation from the Sun.
+func (suDay SuriyaDay) TrueTithi() int {
+	elongation := suDay.TrueMoon.Sub(suDay.TrueSun).Normalize()
+	return int(elongation/NewRal(0, 12, 0)) % 30
 }
 
 // Steps resolved with the answers at:
 // http://astronomy.stackexchange.com/questions/12052/from-mean-moon-to-true-moon-in-an-old-procedural-calendar
 // http://astronomy.stackexchange.com/questions/11753/how-to-interpret-this-old-degree-notation
 
+// A SuriyaDay of a day of the lunar year, from 0 to YearLength() - 1. Returns
+// a YearRangeError or a DayRangeError when out of range.
+func NewSuriyaDay(ce_year int, lunar_year_day int) (SuriyaDay, error) {
+	var suDay SuriyaDay
+
+	suYear, err := NewSuriyaYear(ce_year)
+	if err != nil {
+		return suDay, err
+	}
+
+	if lunar_year_day < 0 || lunar_year_day >= suYear.YearLength() {
+		return suDay, DayRangeError{Year: ce_year, Day: lunar_year_day, YearLength: suYear.YearLength()}
+	}
+
+	suDay.Init(ce_year, lunar_year_day)
+	return suDay, nil
+}
+
 func (suDay *SuriyaDay) Init(ce_year int, lunar_year_day int) {
+	suDay.InitWith(ce_year, lunar_year_day, FloatArithmetic)
+}
+
+// Init with the arithmetic of the calculation, FloatArithmetic or ExactArithmetic.
+func (suDay *SuriyaDay) InitWith(ce_year int, lunar_year_day int, arithmetic int) {
+	suDay.init(ce_year, lunar_year_day, arithmetic, nil)
+}
+
+// InitWith, recording the steps in ex when not nil.
+func (suDay *SuriyaDay) init(ce_year int, lunar_year_day int, arithmetic int, ex *Explanation) {
 	suYear := SuriyaYear{}
 	suYear.Init(ce_year)
 
+	ex.add("A", "Horakhun of the New Year", suYear.Horakhun)
+	ex.add("A", "Kammacubala of the New Year", suYear.Kammacubala)
+	ex.add("A", "Uccabala of the New Year", suYear.Uccabala)
+	ex.add("A", "Tithi of the New Year", suYear.Tithi)
+
 	suDay.Year = ce_year
 	suDay.BE_Year = ce_year + BEdiff
 	suDay.CS_Year = ce_year - CSdiff
@@ -40,31 +139,47 @@ func (suDay *SuriyaDay) Init(ce_year int, lunar_year_day int) {
 	// This is elapsedDays = suDay.Horakhun - suYear.Horakhun, but the meaning is
 	// perhaps clearer as below.
 	elapsedDays := suDay.Day - suYear.Tithi
+	ex.add("A", "Elapsed days", elapsedDays)
 
 	// Horakhun of the day
 	suDay.Horakhun = suYear.Horakhun + elapsedDays
+	ex.add("A", "Horakhun", suDay.Horakhun)
 
 	// Kammacubala of the day
 	suDay.Kammacubala = KammacubalaDaily - (suDay.CS_Year*EraDays+EraHorakhun)%EraYears + elapsedDays*KammacubalaDaily
+	ex.add("A", "Kammacubala", suDay.Kammacubala)
 
 	// Uccabala of the day
 	suDay.Uccabala = (suDay.Horakhun + EraUccabala) % 3232
+	ex.add("A", "Uccabala", suDay.Uccabala)
 
 	var ai, bi int // int helpers
 
 	// Avoman of the day
 	ai = (suDay.Horakhun * CycleDaily) + EraAvoman
 	suDay.Avoman = ai % CycleSolar
+	ex.add("A", "Avoman", suDay.Avoman)
 
 	// Masaken of the day
 	bi = int(math.Floor(float64(ai)/CycleSolar)) + EraMasaken + suDay.Horakhun
 	suDay.Masaken = int(math.Floor(float64(bi / MonthLength)))
+	ex.add("A", "Masaken", suDay.Masaken)
 
 	// Tithi of the day
 	suDay.Tithi = bi % MonthLength
+	ex.add("A", "Tithi", suDay.Tithi)
 
 	var a, b float64
 
+	// Records the Ral of ExactArithmetic, or the degrees FloatArithmetic used
+	addAngle := func(step string, label string, r Ral, degree float64) {
+		if arithmetic == ExactArithmetic {
+			ex.add(step, label, r)
+		} else {
+			ex.add(step, label, degree)
+		}
+	}
+
 	// === B. Find the position of the Mean and true Sun on Asalha 15 ===
 
 	// Sample values in the comments are for lunar_year_day = 103, Asalha 15
@@ -81,45 +196,71 @@ func (suDay *SuriyaDay) Init(ce_year int, lunar_year_day int) {
 
 	a = float64((elapsedDays * EraYears) + suYear.Kammacubala)
 	// a = 64552
+	ex.add("B", "Elapsed days * 800 + Kammacubala", int(a))
 
 	b = (a / EraDays) * 360
 	// b = 79.5282796100025
 
 	// The -3 arcmin is a geographical correction. Mentioned in "Interpolation..." and "Calendrical".
 
-	// (x; y : z) in Eade's notation means 30*60*x + 60*y + z in arcmins, so x and y are deg originally
-	x, y, z := DegreeToRal(b)
-	z -= 3
+	/* FloatArithmetic keeps the degrees in float64 through the steps, floored to
+	four decimal places as the manual's worksheet was reproduced, and gives the
+	Ral fields as the whole arcmins of the degrees. The results are often an
+	arcmin less than Eade's. ExactArithmetic keeps whole arcmins at each step. */
 
+	var meanSun, trueSun float64
+
+	// (x; y : z) in Eade's notation means 30*60*x + 60*y + z in arcmins, so x and y are deg originally
+	if arithmetic == ExactArithmetic {
+		suDay.MeanSun = Ral(int64((elapsedDays*EraYears)+suYear.Kammacubala) * RalCircle / EraDays).Sub(NewRal(0, 0, 3))
+	} else {
+		suDay.MeanSun = DegreeRal(b).Sub(NewRal(0, 0, 3))
+	}
 	// Do convert the degree to Ral and back. If we only do b -= 3/60, we get
 	// slightly different results than in Eade's papers.
-
-	suDay.MeanSun = RalToDegree(x, y, z)
+	x, y, z := suDay.MeanSun.Parts()
+	meanSun = RalToDegree(x, y, z)
 	// MeanSun = 2; 19 : 28
 	// MeanSun = 79.4666
+	ex.add("B", "Mean Sun before the correction", suDay.MeanSun.Add(NewRal(0, 0, 3)))
+	ex.add("B", "Geographical correction", NewRal(0, 0, -3))
+	ex.add("B", "Mean Sun", suDay.MeanSun)
 
 	// The -80 degree is mentioned in Calendrical, sth to do with the Sun's Apogee?
 
-	a = math.Abs(suDay.MeanSun - 80)
+	a = math.Abs(meanSun - 80)
 
 	// math.Sin takes radians
 	radconv := math.Pi / 180
-	b = math.Floor(134 * math.Sin(a*radconv))
+	if arithmetic == ExactArithmetic {
+		b = float64(jya(sunJyaTable, suDay.MeanSun.Sub(NewRal(2, 20, 0)).Abs()))
+	} else {
+		b = math.Floor(134 * math.Sin(a*radconv))
+	}
 	// b = math.Floor(1.2473)
 	// b = 1
-
-	// Floor it to get degree only to 4th decimal place, to avoid results such as TrueSun: 79.48326666666667
-	suDay.TrueSun = math.Floor(suDay.MeanSun*10000+(b*10000)/60) / 10000
+	addAngle("B", "Anomaly of the Sun", suDay.MeanSun.Sub(NewRal(2, 20, 0)).Abs(), a)
+	ex.add("B", "Equation of the Sun", Ral(b))
+
+	// b is in arcmins
+	if arithmetic == ExactArithmetic {
+		suDay.TrueSun = suDay.MeanSun.Add(Ral(b))
+	} else {
+		// Floor it to get degree only to 4th decimal place, to avoid results such as TrueSun: 79.48326666666667
+		trueSun = math.Floor(meanSun*10000+(b*10000)/60) / 10000
+		suDay.TrueSun = DegreeRal(trueSun)
+	}
 	// TrueSun = 2; 19 : 29
+	addAngle("B", "True Sun", suDay.TrueSun, trueSun)
 
 	// === C. Find the Mean and True Moon on Asalha 15 ===
 
 	// step 12.
 
-	// divide with 60 to covert value in degrees from minutes
-	a = (float64(suDay.Avoman) + math.Floor(float64(suDay.Avoman)/25)) / 60
+	// in arcmins
+	avomanRal := Ral(suDay.Avoman + suDay.Avoman/25)
 	// 0; 4 : 17
-	// b = 4.3
+	ex.add("C12", "Avoman in arcmins", avomanRal)
 
 	// step 13.
 
@@ -127,21 +268,37 @@ func (suDay *SuriyaDay) Init(ce_year int, lunar_year_day int) {
 	routine subtraction of 3 arcmins is a geographical longitude correction for
 	the sun, as is the subtraction of 40 arcmins for the moon (sec. C13). */
 
-	// Use RalToDegree() instead of 40/60. RalToDegree() gives only a four decimal
-	// place value, which produces results closer to Eade's papers.
-
-	suDay.MeanMoon = NormalizeDegree(suDay.TrueSun + a + (float64(suDay.Tithi) * 12) - RalToDegree(0, 0, 40))
+	var meanMoon, trueMoon, raek float64
+
+	// 12 degrees for each tithi
+	if arithmetic == ExactArithmetic {
+		suDay.MeanMoon = suDay.TrueSun.Add(avomanRal).Add(NewRal(0, 12*suDay.Tithi, 0)).Sub(NewRal(0, 0, 40)).Normalize()
+	} else {
+		// Use RalToDegree() instead of 40/60. RalToDegree() gives only a four decimal
+		// place value, which produces results closer to Eade's papers.
+		meanMoon = NormalizeDegree(trueSun + avomanRal.Degree() + (float64(suDay.Tithi) * 12) - RalToDegree(0, 0, 40))
+		suDay.MeanMoon = DegreeRal(meanMoon)
+	}
 	// Mean Moon: 8; 11 : 7
 	// Mean Moon: 251.116666
+	ex.add("C13", "Tithi in degrees", NewRal(0, 12*suDay.Tithi, 0))
+	ex.add("C13", "Geographical correction", NewRal(0, 0, -40))
+	addAngle("C13", "Mean Moon", suDay.MeanMoon, meanMoon)
 
 	// step 14.
 
 	var meanUccabala float64
+	meanUccabalaRal := Ral((suYear.Uccabala+elapsedDays)*3*30*60/808 + 2)
 
 	// all in one, see below for step-by-step
-	meanUccabala = ((((float64(suYear.Uccabala+elapsedDays) * 3 * 30) / 808) * 60) + 2) / 60
+	if arithmetic == ExactArithmetic {
+		meanUccabala = meanUccabalaRal.Degree()
+	} else {
+		meanUccabala = ((((float64(suYear.Uccabala+elapsedDays) * 3 * 30) / 808) * 60) + 2) / 60
+	}
 	// Mean Uccabala = 6; 27 : 12
 	// Mean Uccabala = 207.2115
+	addAngle("C14", "Mean Uccabala", meanUccabalaRal, meanUccabala)
 
 	/*
 		Multiply with 30 to conform with (x; y : z) = 30*60*x + 60*y + z
@@ -169,29 +326,48 @@ func (suDay *SuriyaDay) Init(ce_year int, lunar_year_day int) {
 
 	// step 15.
 
-	a = suDay.MeanMoon - meanUccabala
+	a = meanMoon - meanUccabala
+	anomaly := suDay.MeanMoon.Sub(meanUccabalaRal).Normalize()
 	// b = 1; 13 : 54
 	// b = 43.9051
+	addAngle("C15", "Anomaly of the Moon", anomaly, a)
 
 	// NOTE: Eade has 1; 3 : 55, but that doesn't work. This is a typo in the paper.
 
 	// step 16.
 
-	b = (296 * math.Sin(a*radconv)) / 60
+	if arithmetic == ExactArithmetic {
+		b = float64(jya(moonJyaTable, anomaly)) / 60
+	} else {
+		b = (296 * math.Sin(a*radconv)) / 60
+	}
 	// d = 0; 3 : 24
 	// d = 3.4
+	addAngle("C16", "Equation of the Moon", Ral(b*60), b)
 
 	// step 17.
 
-	suDay.TrueMoon = math.Floor((suDay.MeanMoon-b)*10000) / 10000
+	if arithmetic == ExactArithmetic {
+		// The table gives whole arcmins
+		suDay.TrueMoon = suDay.MeanMoon.Sub(Ral(b * 60)).Normalize()
+	} else {
+		trueMoon = math.Floor((meanMoon-b)*10000) / 10000
+		suDay.TrueMoon = DegreeRal(trueMoon)
+	}
 	// True Moon = 8; 7 : 43
 	// True Moon = 247.716666
+	addAngle("C17", "True Moon", suDay.TrueMoon, trueMoon)
 
 	// (0; 13:20) = 13.33 degree is one raek, i.e. 360 deg / 27 mansions
-	a = RalToDegree(0, 13, 20)
-	// Raek aka Mula
-	suDay.Raek = suDay.TrueMoon/a + 1
+	// Raek aka Mula, the fraction in arcmins
+	if arithmetic == ExactArithmetic {
+		suDay.Raek = suDay.TrueMoon*RalAngsa/NewRal(0, 13, 20) + NewRal(0, 1, 0)
+	} else {
+		raek = trueMoon/RalToDegree(0, 13, 20) + 1
+		suDay.Raek = DegreeRal(raek)
+	}
 	// Raek = 0; 19 : 34
 	// Raek = 19.5771
+	addAngle("C18", "Raek", suDay.Raek, raek)
 
 }
@@ -1,8 +1,14 @@
 package suriya
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestAdhikamasa(t *testing.T) {
@@ -202,8 +208,8 @@ func TestSuriyaDayInit(t *testing.T) {
 		Kammacubala: 64552,
 		Uccabala:    1860,
 		Tithi:       14,
-		TrueSun:     79.4832,
-		TrueMoon:    247.6955,
+		TrueSun:     NewRal(2, 19, 28),
+		TrueMoon:    NewRal(8, 7, 41),
 	}
 	expectSuDays = append(expectSuDays, su)
 
@@ -218,8 +224,8 @@ func TestSuriyaDayInit(t *testing.T) {
 		Kammacubala: 84188,
 		Uccabala:    1486,
 		Tithi:       14,
-		TrueSun:     104.5499,
-		TrueMoon:    275.4053,
+		TrueSun:     NewRal(3, 14, 32),
+		TrueMoon:    NewRal(9, 5, 24),
 	}
 	expectSuDays = append(expectSuDays, su)
 
@@ -234,8 +240,8 @@ func TestSuriyaDayInit(t *testing.T) {
 		Kammacubala: 72188,
 		Uccabala:    1471,
 		Tithi:       29,
-		TrueSun:     89.2166,
-		TrueMoon:    86.5874,
+		TrueSun:     NewRal(2, 29, 12),
+		TrueMoon:    NewRal(2, 26, 35),
 	}
 	expectSuDays = append(expectSuDays, su)
 
@@ -263,8 +269,6 @@ TrueMoon: %v
 
 		if suStr != expectSuStr {
 			t.Errorf("expected: %s\n but got: %s\n", expectSuStr, suStr)
-			fmt.Printf("True Sun: %v\n", DegreeToRalString(su.TrueSun))
-			fmt.Printf("True Moon: %v\n", DegreeToRalString(su.TrueMoon))
 		}
 	}
 }
@@ -370,18 +374,18 @@ func TestRaek(t *testing.T) {
 	// CS 1325, Raek 0; 19 : 34
 	// CS 1325 is adhikavāra
 	suDay.Init(1963, 103)
-	expect := "0:19°34'"
-	str := DegreeToRalString(suDay.Raek)
+	expect := "0; 19 : 34"
+	str := suDay.Raek.String()
 	if str != expect {
 		t.Errorf("expected %s, but got %s", expect, str)
 	}
-	expect = "2:19°28'"
-	str = DegreeToRalString(suDay.TrueSun)
+	expect = "2; 19 : 28" // Eade has 2; 19 : 29, ExactArithmetic gives it
+	str = suDay.TrueSun.String()
 	if str != expect {
 		t.Errorf("expected %s, but got %s", expect, str)
 	}
-	expect = "8:7°41'"
-	str = DegreeToRalString(suDay.TrueMoon)
+	expect = "8; 7 : 41" // Eade has 8; 7 : 43
+	str = suDay.TrueMoon.String()
 	if str != expect {
 		t.Errorf("expected %s, but got %s", expect, str)
 	}
@@ -389,8 +393,8 @@ func TestRaek(t *testing.T) {
 	// CS 1324, Raek 0; 20 : 38
 	// CS 1324 is common year
 	suDay.Init(1962, 103)
-	expect = "0:20°39'" // +1 arcmin diff to the value in the paper, probably rounding differences
-	str = DegreeToRalString(suDay.Raek)
+	expect = "0; 20 : 39" // +1 arcmin diff to the value in the paper, probably rounding differences
+	str = suDay.Raek.String()
 	if str != expect {
 		t.Errorf("expected %s, but got %s", expect, str)
 	}
@@ -399,8 +403,8 @@ func TestRaek(t *testing.T) {
 	// 15 days before Asalha
 	// 2015 is adhikamāsa
 	suDay.Init(2015, 103+30-15)
-	expect = "2:26°35'" // myhora.com: Moon is (2; 26 : 12)
-	str = DegreeToRalString(suDay.TrueMoon)
+	expect = "2; 26 : 35" // myhora.com: Moon is (2; 26 : 12)
+	str = suDay.TrueMoon.String()
 	if str != expect {
 		t.Errorf("expected %s, but got %s", expect, str)
 	}
@@ -408,13 +412,13 @@ func TestRaek(t *testing.T) {
 	// 1288-04-14
 	// Example cited in Calendrical.
 	suDay.Init(1288, 41)
-	expect = "0:19°58'"
-	str = DegreeToRalString(suDay.TrueSun)
+	expect = "0; 19 : 58"
+	str = suDay.TrueSun.String()
 	if str != expect {
 		t.Errorf("expected %s, but got %s", expect, str)
 	}
-	expect = "5:11°27'"
-	str = DegreeToRalString(suDay.TrueMoon)
+	expect = "5; 11 : 27"
+	str = suDay.TrueMoon.String()
 	if str != expect {
 		t.Errorf("expected %s, but got %s", expect, str)
 	}
@@ -422,13 +426,13 @@ func TestRaek(t *testing.T) {
 	// 1288-06-15
 	// Common year, Asalha Puja. Date is Full Moon on AstroPixels.
 	suDay.Init(1288, 103)
-	expect = "2:19°9'"
-	str = DegreeToRalString(suDay.TrueSun)
+	expect = "2; 19 : 9"
+	str = suDay.TrueSun.String()
 	if str != expect {
 		t.Errorf("expected %s, but got %s", expect, str)
 	}
-	expect = "8:19°1'"
-	str = DegreeToRalString(suDay.TrueMoon)
+	expect = "8; 19 : 1"
+	str = suDay.TrueMoon.String()
 	if str != expect {
 		t.Errorf("expected %s, but got %s", expect, str)
 	}
@@ -474,8 +478,8 @@ func TestDay(t *testing.T) {
 	suDay.Init(638, 1)
 	expect = `Horakhun: 1
 Date: 0638 March 25
-True Sun: 0:2°38'
-True Moon: 0:20°30'
+True Sun: 0; 2 : 38
+True Moon: 0; 20 : 30
 Tithi: 1
 `
 	str = fmt.Sprintf(`Horakhun: %v
@@ -486,8 +490,8 @@ Tithi: %v
 `,
 		suDay.Horakhun,
 		HorakhunToDate(int64(suDay.Horakhun)).Format("2006 January 2"),
-		DegreeToRalString(suDay.TrueSun),
-		DegreeToRalString(suDay.TrueMoon),
+		suDay.TrueSun,
+		suDay.TrueMoon,
 		suDay.Tithi,
 	)
 	if str != expect {
@@ -500,8 +504,8 @@ Tithi: %v
 Date: 1566 Jan 3
 Horakhun: 338865
 Tithi: 2
-True Sun: 8:25°29'
-True Moon: 9:20°50'
+True Sun: 8; 25 : 29
+True Moon: 9; 20 : 50
 `
 	str = fmt.Sprintf(`Day: %v
 Date: %v
@@ -514,11 +518,1392 @@ True Moon: %v
 		HorakhunToDate(int64(suDay.Horakhun)).Format("2006 Jan 2"),
 		suDay.Horakhun,
 		suDay.Tithi,
-		DegreeToRalString(suDay.TrueSun),
-		DegreeToRalString(suDay.TrueMoon),
+		suDay.TrueSun,
+		suDay.TrueMoon,
 	)
 	if str != expect {
 		t.Errorf("expected %s, but got %s", expect, str)
 	}
 
 }
+
+func TestCalculatePreviousKattika(t *testing.T) {
+	testYears := map[int]string{
+		2014: "2013-11-17",
+		2015: "2014-11-06",
+		2016: "2015-11-25", // the epoch date of the former stepping method
+		2017: "2016-11-14",
+	}
+
+	for year, expect := range testYears {
+		str := CalculatePreviousKattika(year).Format("2006-01-02")
+		if str != expect {
+			t.Errorf("expected %s, but got %s", expect, str)
+		}
+	}
+
+	// The Kattika of consecutive years should be one lunar year apart, far
+	// from the years above as well.
+	for year := 638; year <= 3000; year++ {
+		su := SuriyaYear{}
+		su.Init(year)
+		days := int(CalculatePreviousKattika(year+1).Sub(CalculatePreviousKattika(year)).Hours() / 24)
+		if days != su.YearLength() {
+			t.Errorf("%d: expected %d days between Kattika, but got %d", year, su.YearLength(), days)
+		}
+	}
+}
+
+func TestDateToLunarDate(t *testing.T) {
+	testDates := map[string]string{
+		"2013-01-11": "waning 14, month 1 (Māgasira), BE 2556, CS 1374, Hemanta 3/8",    // New Moon
+		"2015-07-30": "waxing 15, month 13 (2nd Āsāḷha), BE 2558, CS 1377, Gimha 10/10", // Asalha Puja, adhikamāsa
+		"2015-11-26": "waning 1, month 12 (Kattika), BE 2558, CS 1377, Hemanta 1/8",
+		"2016-05-20": "waxing 15, month 6 (Vesākha), BE 2559, CS 1378, Gimha 4/8", // Vesakha Puja
+	}
+
+	for date, expect := range testDates {
+		d, _ := time.Parse("2006-01-02", date)
+		str := DateToLunarDate(d).String()
+		if str != expect {
+			t.Errorf("%s: expected %s, but got %s", date, expect, str)
+		}
+	}
+}
+
+func TestLunarDateToDate(t *testing.T) {
+	// Round trip for every day
+	date, _ := time.Parse("2006-01-02", "1990-01-01")
+	to_date, _ := time.Parse("2006-01-02", "2030-12-31")
+	for ; !date.After(to_date); date = date.AddDate(0, 0, 1) {
+		ld := DateToLunarDate(date)
+		res, err := LunarDateToDate(ld)
+		if err != nil {
+			t.Errorf("%s: %v", ld, err)
+		} else if !res.Equal(date) {
+			t.Errorf("%s: expected %s, but got %s", ld, date.Format("2006-01-02"), res.Format("2006-01-02"))
+		}
+	}
+
+	invalidDates := []LunarDate{
+		{BE_Year: 2556, LunarMonth: 1, Phase: "waning", Day: 15}, // 29 day month
+		{BE_Year: 2559, LunarMonth: 13, Phase: "waxing", Day: 1}, // not adhikamāsa
+		{BE_Year: 2559, LunarMonth: 6, Phase: "waxing", Day: 16}, // out of range
+		{BE_Year: 2559, LunarMonth: 14, Phase: "waxing", Day: 1}, // no such month
+	}
+
+	for _, ld := range invalidDates {
+		if res, err := LunarDateToDate(ld); err == nil {
+			t.Errorf("%v: expected error, but got %s", ld, res.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestDhammayutUposatha(t *testing.T) {
+	for year := 1990; year <= 2030; year++ {
+		var last_date time.Time
+		mahanikaya := GenerateSolarYear(year)
+		dhammayut := GenerateCalendarSolarYear(year, CalendarToInt("dhammayut"))
+
+		var m_uposathas, d_uposathas []UposathaMoon
+		for _, e := range mahanikaya {
+			if u, ok := e.(UposathaMoon); ok {
+				m_uposathas = append(m_uposathas, u)
+			}
+		}
+		for _, e := range dhammayut {
+			if u, ok := e.(UposathaMoon); ok {
+				d_uposathas = append(d_uposathas, u)
+			}
+		}
+
+		for _, u := range d_uposathas {
+			if u.Calendar != 1 {
+				t.Errorf("%s: expected Calendar 1, but got %d", u.Date, u.Calendar)
+			}
+			if u.U_Days != 14 && u.U_Days != 15 {
+				t.Errorf("%s: expected 14 or 15 uposatha days, but got %d", u.Date, u.U_Days)
+			}
+			if !last_date.IsZero() {
+				days := int(u.Date.Sub(last_date).Hours() / 24)
+				if days != u.U_Days {
+					t.Errorf("%s: expected %d days from the last uposatha, but got %d", u.Date, u.U_Days, days)
+				}
+			}
+			last_date = u.Date
+
+			// Find the Mahānikāya uposatha of the same month and phase.
+			for _, m := range m_uposathas {
+				if m.LunarMonth == u.LunarMonth && m.Phase == u.Phase && m.LunarYear == u.LunarYear {
+					days := int(u.Date.Sub(m.Date).Hours() / 24)
+					if days < -1 || days > 1 {
+						t.Errorf("%s: %d days from the Mahānikāya uposatha %s", u.Date, days, m.Date)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDhammayutMoon(t *testing.T) {
+	// The Pakkhagaṇanā keeps the mean Moon, the uposatha is on the day of the
+	// true phase in Bangkok or a day apart when it is far from the mean.
+	ict := time.FixedZone("ICT", 7*3600)
+	on_day := 0
+	total := 0
+	for _, m := range CalculateAstroMoons(time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		if m.Phase != "new" && m.Phase != "full" {
+			continue
+		}
+		tm := m.Date.In(ict)
+		day := time.Date(tm.Year(), tm.Month(), tm.Day(), 0, 0, 0, 0, time.UTC)
+		u := PakkhaUposathaDate(day, m.Phase)
+		days := int(u.Sub(day).Hours() / 24)
+		if days < -1 || days > 1 {
+			t.Errorf("%s: expected the %s uposatha within a day, but got %s", day.Format("2006-01-02"), m.Phase, u.Format("2006-01-02"))
+		}
+		if days == 0 {
+			on_day++
+		}
+		total++
+	}
+	if on_day*2 < total {
+		t.Errorf("expected most uposathas on the day of the phase, but got %d of %d", on_day, total)
+	}
+
+	if PakkhaCycle*15-PakkhaCycleDays != PakkhaCycleShort {
+		t.Errorf("expected %d short pakkhas, but got %d", PakkhaCycleShort, PakkhaCycle*15-PakkhaCycleDays)
+	}
+	if pakkhaDay(PakkhaCycle)-pakkhaDay(0) != PakkhaCycleDays {
+		t.Errorf("expected %d days in a sampayuha, but got %d", PakkhaCycleDays, pakkhaDay(PakkhaCycle)-pakkhaDay(0))
+	}
+}
+
+func TestPoya(t *testing.T) {
+	expectPoyas := map[string]string{
+		"2015-01-04": "Duruthu Full Moon Poya Day",
+		"2015-05-03": "Vesak Full Moon Poya Day",
+		"2015-07-01": "Adhi Esala Full Moon Poya Day", // adhikamāsa
+		"2015-07-30": "Esala Full Moon Poya Day",
+		"2015-11-25": "Il Full Moon Poya Day",
+		"2018-04-29": "Vesak Full Moon Poya Day",
+		"2018-06-27": "Poson Full Moon Poya Day",    // after Adhi Poson
+		"2020-10-01": "Adhi Vap Full Moon Poya Day", // not a Thai adhikamāsa year
+		"2020-10-30": "Vap Full Moon Poya Day",
+	}
+
+	found := make(map[string]bool)
+	for _, year := range []int{2015, 2018, 2020} {
+		for _, e := range GenerateCalendarSolarYear(year, CalendarToInt("srilanka")) {
+			if m, ok := e.(MajorEvent); ok {
+				date := m.Date.Format("2006-01-02")
+				if m.Summary == expectPoyas[date] {
+					found[date] = true
+				}
+			}
+		}
+	}
+
+	for date, expect := range expectPoyas {
+		if !found[date] {
+			t.Errorf("%s: expected %s", date, expect)
+		}
+	}
+
+	// A week after the 2015-01-04 Full Moon, no Poya tithi is near
+	date := time.Date(2015, 1, 11, 0, 0, 0, 0, time.UTC)
+	if res, err := PoyaDate(date, "full"); err == nil {
+		t.Errorf("expected error, but got %s", res.Format("2006-01-02"))
+	}
+
+	for _, year := range []int{2017, 2019, 2021} {
+		for _, e := range GenerateCalendarSolarYear(year, CalendarToInt("srilanka")) {
+			if m, ok := e.(MajorEvent); ok && strings.HasPrefix(m.Summary, "Adhi") {
+				t.Errorf("%d: expected no Adhi month, but got %s on %s", year, m.Summary, m.Date.Format("2006-01-02"))
+			}
+		}
+	}
+}
+
+func TestMyanmarDate(t *testing.T) {
+	testDates := map[string]string{
+		"2015-07-01": "full 15, First Waso, ME 1377", // watat year
+		"2015-07-31": "full 15, Waso, ME 1377",
+		"2016-05-21": "full 15, Kason, ME 1378",
+		"2017-04-11": "full 15, Late Tagu, ME 1378",
+		"2017-05-10": "full 15, Kason, ME 1379",
+		"2017-10-05": "full 15, Thadingyut, ME 1379",
+		"2017-10-06": "waning 1, Thadingyut, ME 1379",
+	}
+
+	for date, expect := range testDates {
+		d, _ := time.Parse("2006-01-02", date)
+		str := DateToMyanmarDate(d).String()
+		if str != expect {
+			t.Errorf("%s: expected %s, but got %s", date, expect, str)
+		}
+	}
+
+	for year := 1990; year <= 2030; year++ {
+		for _, u := range MyanmarUposathas(year) {
+			if u.U_Days != 14 && u.U_Days != 15 {
+				t.Errorf("%s: expected 14 or 15 uposatha days, but got %d", u.Date, u.U_Days)
+			}
+		}
+	}
+}
+
+func TestKhmer(t *testing.T) {
+	expectEvents := map[string]string{
+		"2017-02-11": "Meak Bochea",
+		"2017-05-10": "Visak Bochea",
+		"2017-09-20": "Pchum Ben",
+		"2017-10-05": "Chenh Vassa",
+	}
+
+	found := make(map[string]bool)
+	for _, e := range GenerateCalendarSolarYear(2017, CalendarToInt("khmer")) {
+		if m, ok := e.(MajorEvent); ok {
+			date := m.Date.Format("2006-01-02")
+			if m.Summary == expectEvents[date] {
+				found[date] = true
+			}
+		}
+	}
+
+	for date, expect := range expectEvents {
+		if !found[date] {
+			t.Errorf("%s: expected %s", date, expect)
+		}
+	}
+
+	// The cases of the Chhankitek rules
+	testYears := []struct {
+		year     int
+		bodithey int
+		avoman   int
+		days     int
+	}{
+		{2012, 24, 400, 384}, // followed by 6, the extra month
+		{2014, 17, 137, 354}, // followed by Avoman 0, common
+		{2015, 28, 0, 384},   // the extra month and the extra day
+		{2016, 9, 566, 355},  // the extra day carried from 2015
+		{2017, 20, 429, 354},
+	}
+	for _, ty := range testYears {
+		var ky KhmerYear
+		ky.Init(ty.year)
+		if ky.Bodithey != ty.bodithey || ky.Avoman != ty.avoman {
+			t.Errorf("%d: expected Bodithey %d and Avoman %d, but got %d and %d", ty.year, ty.bodithey, ty.avoman, ky.Bodithey, ky.Avoman)
+		}
+		if n := ky.YearLength(); n != ty.days {
+			t.Errorf("%d: expected %d days, but got %d", ty.year, ty.days, n)
+		}
+	}
+}
+
+func TestPlanets(t *testing.T) {
+	var expect, str string
+
+	// Sidereal, as the Sūrya Siddhānta. Venus and Jupiter were in conjunction
+	// on June 30 at about 115 degrees. The revolutions of the Sūrya Siddhānta
+	// put them 7.5 degrees apart, with the errors of the dated events below.
+	date, _ := time.Parse("2006-01-02", "2015-07-01")
+	suDay := SuriyaDay{}
+	suDay.InitDate(date)
+
+	expect = `Sun: 2; 15 : 15
+Moon: 8; 12 : 10
+Mercury: 1; 27 : 52
+Venus: 3; 23 : 34
+Mars: 2; 9 : 38
+Jupiter: 4; 1 : 4
+Saturn: 6; 28 : 20
+Rahu: 5; 15 : 12
+Ketu: 11; 15 : 12
+`
+	str = suDay.Planets().String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// The Wat Kiat casting of TestDay. The inscription gives only the rāsi of
+	// the Sun and Moon, the planets are as this reckoning has them.
+	suDay.Init(1565, 298)
+	expect = `Sun: 8; 25 : 29
+Moon: 9; 20 : 50
+Mercury: 8; 23 : 31
+Venus: 10; 5 : 2
+Mars: 2; 1 : 24
+Jupiter: 5; 24 : 47
+Saturn: 4; 11 : 11
+Rahu: 7; 13 : 53
+Ketu: 1; 13 : 53
+`
+	str = suDay.Planets().String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// Mean positions of the first day of the Era
+	suDay.Init(638, 1)
+	sp := suDay.Planets()
+	if sp.Mercury.Mean != sp.Sun.Mean || sp.Venus.Mean != sp.Sun.Mean {
+		t.Errorf("expected the mean Sun for Mercury and Venus, but got %v and %v", sp.Mercury.Mean, sp.Venus.Mean)
+	}
+	expect = "6; 0 : 0"
+	str = sp.Ketu.True.Sub(sp.Rahu.True).Normalize().String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// Dated events which give the longitude of a planet from the Sun's: a
+	// transit or conjunction is at the Sun, an opposition opposite to it, and
+	// a greatest elongation at its angle from the Sun. The Sūrya Siddhānta
+	// without the bīja corrections has Jupiter ahead and Saturn behind by
+	// several degrees in modern times, the others are within a few degrees.
+	testEvents := []struct {
+		date       string
+		planet     string
+		elongation float64
+		limit      float64
+	}{
+		{"2016-05-09", "mercury", 0, 2},   // transit of Mercury
+		{"2014-10-25", "venus", 0, 3},     // superior conjunction
+		{"2015-06-06", "venus", 45.4, 2},  // greatest eastern elongation
+		{"2015-10-26", "venus", -46.4, 2}, // greatest western elongation
+		{"2015-06-14", "mars", 0, 3},      // conjunction
+		{"2015-02-06", "jupiter", 180, 6}, // opposition
+		{"2015-05-22", "saturn", 180, 8},  // opposition
+	}
+	for _, e := range testEvents {
+		date, _ := time.Parse("2006-01-02", e.date)
+		suDay.InitDate(date)
+		sp := suDay.Planets()
+		found := map[string]Ral{"mercury": sp.Mercury.True, "venus": sp.Venus.True, "mars": sp.Mars.True, "jupiter": sp.Jupiter.True, "saturn": sp.Saturn.True}[e.planet]
+
+		// at the dawn of the Suriyayatra day in Bangkok
+		tm := time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, LongitudeLocation(100.5)).Add(SuriyaDawn)
+		modern := normalizeLongitude(SunLongitude(tm) + e.elongation - LahiriAyanamsa(tm))
+
+		if d := longitudeDifference(found.Degree(), modern); math.Abs(d) > e.limit {
+			t.Errorf("%s: expected %s within %.0f degrees of %.2f, but got %.2f", e.date, e.planet, e.limit, modern, found.Degree())
+		}
+	}
+
+	// Rahu and the modern mean ascending node of the Moon
+	for _, year := range []int{1566, 1800, 1963, 2015} {
+		date := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		suDay.InitDate(date)
+		tm := date.AddDate(0, 0, 1).Add(SuriyaDawn)
+		node := normalizeLongitude(moonNode(julianCenturies(timeToJDE(tm))) - LahiriAyanamsa(tm))
+		if d := longitudeDifference(suDay.Planets().Rahu.True.Degree(), node); math.Abs(d) > 5 {
+			t.Errorf("%d: expected Rahu within 5 degrees of %.2f, but got %s", year, node, suDay.Planets().Rahu.True)
+		}
+	}
+}
+
+func TestDuang(t *testing.T) {
+	var expect, str string
+
+	date, _ := time.Parse("2006-01-02", "2015-07-01")
+	duang := DateToDuang(date)
+
+	expect = `Duang of 2015-07-01, Horakhun 503038
+
+ 0 Mesa     |
+ 1 Usabha   | 4
+ 2 Methuna  | 1 3
+ 3 Kakkaṭa  | 6
+ 4 Sīha     | 5
+ 5 Kaññā    | 8
+ 6 Tulā     | 7
+ 7 Vicchika |
+ 8 Dhanu    | 2
+ 9 Makara   |
+10 Kumbha   |
+11 Mīna     | 9
+
+1 Sun      2; 15 : 15
+2 Moon     8; 12 : 10
+3 Mars     2; 9 : 38
+4 Mercury  1; 27 : 52
+5 Jupiter  4; 1 : 4
+6 Venus    3; 23 : 34
+7 Saturn   6; 28 : 20
+8 Rahu     5; 15 : 12
+9 Ketu     11; 15 : 12
+`
+	str = duang.String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	svg := duang.SVG()
+	for _, expect = range []string{"<svg ", ">Methuna</text>", ">1 3</text>", "</svg>"} {
+		if !strings.Contains(svg, expect) {
+			t.Errorf("expected %s in the SVG, but got %s", expect, svg)
+		}
+	}
+
+	a, err := json.Marshal(duang)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	expect = `{"Number":2,"Name":"Moon","Longitude":252.16`
+	if !strings.Contains(string(a), expect) {
+		t.Errorf("expected %s in the JSON, but got %s", expect, string(a))
+	}
+}
+
+func TestSearchInscription(t *testing.T) {
+	var expect, str string
+
+	// The Wat Kiat casting of TestDay, from the values of its duang.
+	q := NewInscriptionQuery(925, 929)
+	q.Weekday = 1
+	q.Tithi = 2
+	q.SunRasi = 8
+	q.MoonRasi = 9
+
+	candidates, err := SearchInscription(q, 3)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	if len(candidates) != 3 {
+		t.Errorf("expected 3 candidates, but got %d", len(candidates))
+		return
+	}
+
+	expect = "1566-01-03, Horakhun 338865, 4 of 4: Monday, waxing 3, month 2 (Phussa), BE 2109, CS 927, Hemanta 4/8"
+	str = candidates[0].String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	if candidates[1].Matches != 3 || len(candidates[1].Mismatches) != 1 {
+		t.Errorf("expected one mismatch, but got %v", candidates[1].Mismatches)
+	}
+
+	q.Day = 16
+	if _, err := SearchInscription(q, 3); err == nil {
+		t.Errorf("expected error for day 16")
+	}
+
+	invalid := map[string]func(q *InscriptionQuery){
+		"month -5":     func(q *InscriptionQuery) { q.LunarMonth = -5 },
+		"month 14":     func(q *InscriptionQuery) { q.LunarMonth = 14 },
+		"day -5":       func(q *InscriptionQuery) { q.Day = -5 },
+		"weekday 7":    func(q *InscriptionQuery) { q.Weekday = 7 },
+		"tithi 30":     func(q *InscriptionQuery) { q.Tithi = 30 },
+		"nakshatra 27": func(q *InscriptionQuery) { q.Nakshatra = 27 },
+		"sun rasi 12":  func(q *InscriptionQuery) { q.SunRasi = 12 },
+		"moon rasi -2": func(q *InscriptionQuery) { q.MoonRasi = -2 },
+	}
+	for name, set := range invalid {
+		q := NewInscriptionQuery(925, 929)
+		set(&q)
+		if _, err := SearchInscription(q, 3); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+}
+
+func TestSongkran(t *testing.T) {
+	var expect, str string
+
+	su := SuriyaYear{}
+	su.Init(2024)
+
+	expect = `Maha Songkran: 2024-04-13 22:17:24, Saturday
+Wan Nao: 2024-04-14, 2024-04-15
+Thaloeng Sok: 2024-04-16 02:15:00
+Wan Thaloeng Sok: 2024-04-16, Tuesday, CS 1386
+Nang Songkran: Mahothon Thewi, reclining with open eyes, mount: peacock
+Rulers: Saturn, Mars
+`
+	str = su.Songkran().String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// Maha Songkran and Thaloeng Sok as announced in the almanac
+	expectSongkran := map[int][3]string{
+		2015: {"2015-04-14 14:24:00", "Raksot Thewi", "2015-04-16 18:21:36"},
+		2022: {"2022-04-14 09:52:12", "Kirini Thewi", "2022-04-16 13:49:48"},
+		2023: {"2023-04-14 16:04:48", "Kimitha Thewi", "2023-04-16 20:02:24"},
+		2024: {"2024-04-13 22:17:24", "Mahothon Thewi", "2024-04-16 02:15:00"},
+		2025: {"2025-04-14 04:30:00", "Khorakha Thewi", "2025-04-16 08:27:36"},
+	}
+	for year, e := range expectSongkran {
+		su.Init(year)
+		sk := su.Songkran()
+		got := [3]string{sk.MahaSongkran.Format("2006-01-02 15:04:05"), sk.NangSongkran, sk.ThaloengSok.Format("2006-01-02 15:04:05")}
+		if got != e {
+			t.Errorf("%d: expected %v, but got %v", year, e, got)
+		}
+	}
+
+	// Wan Thaloeng Sok is the day of the Horakhun
+	for year := 1900; year <= 2100; year++ {
+		su.Init(year)
+		sk := su.Songkran()
+		if !sk.WanThaloengSok.Equal(dayOf(HorakhunToDate(int64(su.Horakhun)))) {
+			t.Errorf("%d: expected Wan Thaloeng Sok on the day of Horakhun %d, but got %s", year, su.Horakhun, sk.WanThaloengSok)
+		}
+		if n := len(sk.WanNao); n != 1 && n != 2 {
+			t.Errorf("%d: expected 1 or 2 days of Wan Nao, but got %d", year, n)
+		}
+	}
+
+	found := map[string]bool{}
+	for _, e := range GenerateSolarYear(2015) {
+		if m, ok := e.(MajorEvent); ok {
+			found[m.Date.Format("2006-01-02")+" "+m.Summary] = true
+		}
+	}
+	for _, expect = range []string{"2015-04-14 Maha Songkran", "2015-04-15 Wan Nao", "2015-04-16 Wan Thaloeng Sok"} {
+		if !found[expect] {
+			t.Errorf("expected %s in the major events", expect)
+		}
+	}
+}
+
+func TestYearName(t *testing.T) {
+	su := SuriyaYear{}
+	su.Init(2015)
+	expect := "Mamae Sapphasok"
+	str := su.AnimalName() + " " + su.DecadeName()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// Expected names in the Songkran, month 5 and month 1 conventions
+	testDates := map[string][3]string{
+		"2015-01-10": {"Mamia Chasok", "Mamia Chasok", "Mamae Sapphasok"},
+		"2015-03-25": {"Mamia Chasok", "Mamae Sapphasok", "Mamae Sapphasok"}, // waxing 6, month 5
+		"2015-04-15": {"Mamia Chasok", "Mamae Sapphasok", "Mamae Sapphasok"}, // Wan Nao
+		"2015-04-16": {"Mamae Sapphasok", "Mamae Sapphasok", "Mamae Sapphasok"},
+		"2015-12-15": {"Mamae Sapphasok", "Mamae Sapphasok", "Wok Atthasok"}, // waxing 5, month 1
+	}
+
+	for date, names := range testDates {
+		d, _ := time.Parse("2006-01-02", date)
+		for changeover, expect := range names {
+			su = DateToNamedYear(d, changeover)
+			str = su.AnimalName() + " " + su.DecadeName()
+			if str != expect {
+				t.Errorf("%s, %d: expected %s, but got %s", date, changeover, expect, str)
+			}
+		}
+	}
+
+	for _, e := range GenerateSolarYear(2015) {
+		if u, ok := e.(UposathaMoon); ok && u.Date.Month() == time.June {
+			str = u.YearAnimal + " " + u.YearDecade
+			if str != "Mamae Sapphasok" {
+				t.Errorf("%s: expected Mamae Sapphasok, but got %s", u.Date, str)
+			}
+		}
+	}
+}
+
+func TestRal(t *testing.T) {
+	// Eade's notation, as pasted from the papers
+	testRals := map[string]Ral{
+		"2; 19 : 28":  NewRal(2, 19, 28),
+		"2;19:28":     NewRal(2, 19, 28),
+		"0; 19 : 34":  NewRal(0, 19, 34),
+		"8; 7 : 43":   NewRal(8, 7, 43),
+		"-0; 0 : 40":  -40,
+		"2:19°28'":    4768,
+		"11; 29 : 59": RalCircle - 1,
+	}
+
+	for str, expect := range testRals {
+		r, err := ParseRal(str)
+		if err != nil {
+			t.Errorf("%s: %v", str, err)
+		} else if r != expect {
+			t.Errorf("%s: expected %d, but got %d", str, expect, r)
+		}
+	}
+
+	for _, str := range []string{"2; 30 : 0", "2; 19 : 60", "2; 19", "x; 1 : 2"} {
+		if r, err := ParseRal(str); err == nil {
+			t.Errorf("%s: expected error, but got %s", str, r)
+		}
+	}
+
+	var expect, str string
+
+	// Mean Sun of 1963, day 103, with the -3 arcmin correction
+	expect = "2; 19 : 28"
+	str = NewRal(2, 19, 31).Sub(NewRal(0, 0, 3)).String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	expect = "0; 0 : 20"
+	str = NewRal(11, 29, 50).Add(NewRal(0, 0, 30)).Normalize().String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	expect = "11; 29 : 20"
+	str = Ral(-40).Normalize().String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	expect = "-0; 0 : 40"
+	str = Ral(-40).String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// Round trip without flooring
+	for r := Ral(0); r < RalCircle; r++ {
+		p, err := ParseRal(r.String())
+		if err != nil || p != r {
+			t.Errorf("%d: expected a round trip, but got %d, %v", r, p, err)
+		}
+	}
+}
+
+func TestExactArithmetic(t *testing.T) {
+	var expect, str string
+
+	// The expanded example in Eade's paper "Rules for Interpolation in The Thai
+	// Calendar", CS 1325, day 103. All the values are as in the paper.
+	suDay := SuriyaDay{}
+	suDay.InitWith(1963, 103, ExactArithmetic)
+
+	expect = `Mean Sun: 2; 19 : 28
+True Sun: 2; 19 : 29
+Mean Moon: 8; 11 : 7
+True Moon: 8; 7 : 43
+Raek: 0; 19 : 34
+`
+	str = fmt.Sprintf(`Mean Sun: %s
+True Sun: %s
+Mean Moon: %s
+True Moon: %s
+Raek: %s
+`, suDay.MeanSun, suDay.TrueSun, suDay.MeanMoon, suDay.TrueMoon, suDay.Raek)
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// The Sun's equation of 0; 0 : 32 from the apogee is 1 arcmin.
+	if n := jya(sunJyaTable, NewRal(0, 0, 32)); n != 1 {
+		t.Errorf("expected 1, but got %d", n)
+	}
+
+	// The Moon's anomaly of 1; 13 : 55 gives d = 0; 3 : 24, as in the paper.
+	if n := jya(moonJyaTable, NewRal(1, 13, 55)); n != 204 {
+		t.Errorf("expected 204, but got %d", n)
+	}
+
+	// The table follows the sine in the four quadrants
+	testJya := map[Ral]int{
+		NewRal(0, 0, 0):  0,
+		NewRal(3, 0, 0):  296,
+		NewRal(4, 0, 0):  256,
+		NewRal(6, 0, 0):  0,
+		NewRal(7, 0, 0):  -148,
+		NewRal(9, 0, 0):  -296,
+		NewRal(11, 0, 0): -148,
+		-NewRal(1, 0, 0): -148,
+	}
+	for anomaly, expect := range testJya {
+		if n := jya(moonJyaTable, anomaly); n != expect {
+			t.Errorf("%s: expected %d, but got %d", anomaly, expect, n)
+		}
+	}
+
+	// The float arithmetic is the default
+	suDay.Init(1963, 103)
+	expect = "8; 7 : 41"
+	str = suDay.TrueMoon.String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+}
+
+func TestJDN(t *testing.T) {
+	testGregorian := map[int64]string{
+		0:       "-4713-11-24",
+		1954167: "0638-03-24", // Horakhun 0
+		2299161: "1582-10-15", // first day of the Gregorian calendar
+		2451545: "2000-01-01",
+	}
+	testJulian := map[int64]string{
+		0:       "-4712-01-01",
+		1954167: "0638-03-21", // Horakhun 0
+		2299160: "1582-10-04", // last day of the Julian calendar
+		2451545: "1999-12-19",
+	}
+
+	for jdn, expect := range testGregorian {
+		y, m, d := JDNToGregorian(jdn)
+		str := fmt.Sprintf("%04d-%02d-%02d", y, m, d)
+		if str != expect {
+			t.Errorf("%d: expected %s, but got %s", jdn, expect, str)
+		}
+		if n := GregorianToJDN(y, m, d); n != jdn {
+			t.Errorf("%s: expected %d, but got %d", expect, jdn, n)
+		}
+	}
+
+	for jdn, expect := range testJulian {
+		y, m, d := JDNToJulian(jdn)
+		str := fmt.Sprintf("%04d-%02d-%02d", y, m, d)
+		if str != expect {
+			t.Errorf("%d: expected %s, but got %s", jdn, expect, str)
+		}
+		if n := JulianToJDN(y, m, d); n != jdn {
+			t.Errorf("%s: expected %d, but got %d", expect, jdn, n)
+		}
+	}
+
+	// The Wat Kiat casting of TestDay is dated Julian in the inscription
+	y, m, d := HorakhunToJulian(338865)
+	if str := fmt.Sprintf("%04d-%02d-%02d", y, m, d); str != "1565-12-24" {
+		t.Errorf("expected 1565-12-24, but got %s", str)
+	}
+	if h := JulianToHorakhun(1565, 12, 24); h != 338865 {
+		t.Errorf("expected 338865, but got %d", h)
+	}
+
+	// Round trips through the era and before it
+	for horakhun := int64(-2000000); horakhun <= 1000000; horakhun += 997 {
+		date := HorakhunToDate(horakhun)
+		if h := DateToHorakhun(date); h != horakhun {
+			t.Errorf("%d: expected a round trip, but got %d", horakhun, h)
+		}
+		y, m, d := JDNToGregorian(HorakhunToJDN(horakhun))
+		if y != date.Year() || m != int(date.Month()) || d != date.Day() {
+			t.Errorf("%d: expected %s, but got %d-%d-%d", horakhun, date.Format("2006-01-02"), y, m, d)
+		}
+		y, m, d = HorakhunToJulian(horakhun)
+		if h := JulianToHorakhun(y, m, d); h != horakhun {
+			t.Errorf("%d: expected a Julian round trip, but got %d", horakhun, h)
+		}
+	}
+}
+
+func TestYearTable(t *testing.T) {
+	table := NewYearTable()
+	table.Precompute(1990, 2000)
+
+	expect := map[int]string{
+		2012: "adhikamasa",
+		2015: "adhikamasa",
+		2016: "adhikavara",
+		2017: "common",
+	}
+	for year, kind := range expect {
+		if str := table.Get(year).Kind(); str != kind {
+			t.Errorf("%d: expected %s, but got %s", year, kind, str)
+		}
+	}
+
+	// The table gives the same as the calculation, with and without the
+	// exceptions, from several goroutines.
+	defer func(use_exceptions bool) {
+		UseExceptions = use_exceptions
+	}(UseExceptions)
+
+	for _, use_exceptions := range []bool{false, true} {
+		UseExceptions = use_exceptions
+
+		var wg sync.WaitGroup
+		for n := 0; n < 4; n++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for year := 1900; year <= 2100; year++ {
+					table.Get(year)
+				}
+			}()
+		}
+		wg.Wait()
+
+		for year := 1900; year <= 2100; year++ {
+			info := table.Get(year)
+			calc := calculateYearInfo(year)
+			if info.IsAdhikamasa != calc.IsAdhikamasa || info.IsAdhikavara != calc.IsAdhikavara ||
+				info.YearLength != calc.YearLength || !info.Kattika.Equal(calc.Kattika) || !info.AsalhaPuja.Equal(calc.AsalhaPuja) {
+				t.Errorf("%d: expected %v, but got %v", year, calc, info)
+			}
+		}
+	}
+
+	table.Reset()
+	if n := len(table.years); n != 0 {
+		t.Errorf("expected an empty table, but got %d years", n)
+	}
+
+	// Without the exceptions, 1997 is not adhikavāra
+	UseExceptions = false
+	var su SuriyaYear
+	su.Init(1997)
+	if su.Is_Adhikavara() || su.YearLength() != 354 {
+		t.Errorf("expected a common year, but got %d days", su.YearLength())
+	}
+	UseExceptions = true
+	if !su.Is_Adhikavara() || su.YearLength() != 355 {
+		t.Errorf("expected an adhikavāra year, but got %d days", su.YearLength())
+	}
+
+	// A year built by hand answers from its own fields. 2015 with the tithi of
+	// a common year is not adhikamāsa.
+	su.Init(2015)
+	su.Tithi = 10
+	if su.Is_Adhikamasa() {
+		t.Errorf("expected not adhikamāsa with tithi %d", su.Tithi)
+	}
+	if n := su.YearLength(); n == 384 {
+		t.Errorf("expected a year without the extra month, but got %d days", n)
+	}
+}
+
+func TestConstructors(t *testing.T) {
+	su, err := NewSuriyaYear(2015)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	if su.CS_Year != 1377 {
+		t.Errorf("expected CS 1377, but got %d", su.CS_Year)
+	}
+
+	for _, year := range []int{-1, 637, 10000} {
+		_, err = NewSuriyaYear(year)
+		if _, ok := err.(YearRangeError); !ok {
+			t.Errorf("%d: expected YearRangeError, but got %v", year, err)
+		}
+	}
+
+	suDay, err := NewSuriyaDay(1963, 103)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	if suDay.Horakhun != 484049 {
+		t.Errorf("expected Horakhun 484049, but got %d", suDay.Horakhun)
+	}
+
+	// 2015 is adhikamāsa, 384 days
+	if _, err = NewSuriyaDay(2015, 383); err != nil {
+		t.Errorf("%v", err)
+	}
+	for _, day := range []int{-1, 384} {
+		_, err = NewSuriyaDay(2015, day)
+		if e, ok := err.(DayRangeError); !ok {
+			t.Errorf("%d: expected DayRangeError, but got %v", day, err)
+		} else if e.YearLength != 384 {
+			t.Errorf("expected YearLength 384, but got %d", e.YearLength)
+		}
+	}
+
+	_, err = NewSuriyaDay(600, 1)
+	if _, ok := err.(YearRangeError); !ok {
+		t.Errorf("expected YearRangeError, but got %v", err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	suDay, ex := ExplainDay(1963, 103, ExactArithmetic)
+
+	// The trace doesn't change the result
+	var plain SuriyaDay
+	plain.InitWith(1963, 103, ExactArithmetic)
+	if suDay != plain {
+		t.Errorf("expected %v, but got %v", plain, suDay)
+	}
+
+	// The steps of Eade's worked example
+	testSteps := map[string]string{
+		"Elapsed days":                   "80",
+		"Mean Sun before the correction": "2; 19 : 31",
+		"Mean Sun":                       "2; 19 : 28",
+		"True Sun":                       "2; 19 : 29",
+		"Mean Moon":                      "8; 11 : 7",
+		"Mean Uccabala":                  "6; 27 : 12",
+		"Anomaly of the Moon":            "1; 13 : 55",
+		"Equation of the Moon":           "0; 3 : 24",
+		"True Moon":                      "8; 7 : 43",
+		"Raek":                           "0; 19 : 34",
+	}
+	for label, expect := range testSteps {
+		var str string
+		for _, st := range ex.Steps {
+			if st.Label == label {
+				str = st.Value
+			}
+		}
+		if str != expect {
+			t.Errorf("%s: expected %s, but got %s", label, expect, str)
+		}
+	}
+
+	// FloatArithmetic records the degrees it used
+	_, ex = ExplainDay(1963, 103, FloatArithmetic)
+	testSteps = map[string]string{
+		"Mean Uccabala":        "207.2116",
+		"Equation of the Moon": "3.4211",
+	}
+	for label, expect := range testSteps {
+		var str string
+		for _, st := range ex.Steps {
+			if st.Label == label {
+				str = st.Value
+			}
+		}
+		if str != expect {
+			t.Errorf("%s: expected %s, but got %s", label, expect, str)
+		}
+	}
+
+	_, ex = ExplainYear(1963)
+	if !strings.Contains(ex.String(), "Horakhun                      483969") {
+		t.Errorf("expected the Horakhun 483969, but got %s", ex)
+	}
+
+	a, err := json.Marshal(ex)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	if !strings.Contains(string(a), `{"Step":"A","Label":"Tithi","Value":"23"}`) {
+		t.Errorf("expected the Tithi 23, but got %s", a)
+	}
+}
+
+func TestMoonPhases(t *testing.T) {
+	// Meeus, Example 49.a, the New Moon of 1977 Feb
+	if jde := moonPhaseJDE(-283, PhaseNew); math.Abs(jde-2443192.65118) > 0.00001 {
+		t.Errorf("expected 2443192.65118, but got %.5f", jde)
+	}
+
+	// Within a few minutes of the Aeris data
+	from := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2016, 12, 31, 23, 59, 59, 0, time.UTC)
+
+	data_moons, err := AerisProvider{}.AstroMoons(from, to)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	moons := CalculateAstroMoons(from, to)
+	if len(moons) != len(data_moons) {
+		t.Errorf("expected %d, but got %d", len(data_moons), len(moons))
+	} else {
+		for i := range moons {
+			diff := moons[i].Date.Sub(data_moons[i].Date)
+			if moons[i].Phase != data_moons[i].Phase || diff > 5*time.Minute || diff < -5*time.Minute {
+				t.Errorf("expected %s %s, but got %s %s", data_moons[i].Phase, data_moons[i].Date, moons[i].Phase, moons[i].Date)
+			}
+		}
+	}
+
+	// Years without data files are calculated
+	moons = GetAstroMoons(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2100, 12, 31, 0, 0, 0, 0, time.UTC))
+	if len(moons) < 48 {
+		t.Errorf("expected at least 48 moons, but got %d", len(moons))
+	}
+	for i := 1; i < len(moons); i++ {
+		if moons[i].Phase != phaseCodes[(indexOfPhase(moons[i-1].Phase)+1)%4] {
+			t.Errorf("expected the phases in order, but got %s after %s", moons[i].Phase, moons[i-1].Phase)
+		}
+	}
+}
+
+func indexOfPhase(phase string) int {
+	for i, p := range phaseCodes {
+		if p == phase {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestAstroMoonProviders(t *testing.T) {
+	var expect, str string
+
+	csv_data := `# from the observatory
+Date,Phase
+2016-01-10 01:31,New Moon
+2016-01-02T05:31:40Z,last quarter
+2016-01-16 23:27,1
+2016-01-24 01:46,full
+`
+	table, err := ParseAstroMoonCSV(strings.NewReader(csv_data))
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	expect = `2016-01-02 05:31 waning
+2016-01-10 01:31 new
+2016-01-16 23:27 waxing
+2016-01-24 01:46 full
+`
+	str = ""
+	for _, m := range table {
+		str += fmt.Sprintf("%s %s\n", m.Date.Format("2006-01-02 15:04"), m.Phase)
+	}
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	if _, err := ParseAstroMoonCSV(strings.NewReader("2016-01-10,gibbous\n")); err == nil {
+		t.Errorf("expected an error for an unknown phase")
+	}
+
+	usno_data := `
+         New Moon       First Quarter       Full Moon       Last Quarter
+
+         d  h  m         d  h  m         d  h  m         d  h  m
+ 2016                                                  Dec 29 06 53
+       Jan 10 01 30    Jan 16 23 26    Jan 24 01 46    Feb  1 03 28
+`
+	table, err = ParseUSNOTable(strings.NewReader(usno_data))
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	expect = `2016-12-29 06:53 waning
+2017-01-10 01:30 new
+2017-01-16 23:26 waxing
+2017-01-24 01:46 full
+2017-02-01 03:28 waning
+`
+	str = ""
+	for _, m := range table {
+		str += fmt.Sprintf("%s %s\n", m.Date.Format("2006-01-02 15:04"), m.Phase)
+	}
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// The table has the moons of January 2016, the rest is calculated
+	table, _ = ParseAstroMoonCSV(strings.NewReader(csv_data))
+	chain := ChainProvider{table, CalculatedProvider{}}
+	from := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2017, 12, 31, 0, 0, 0, 0, time.UTC)
+	moons, err := chain.AstroMoons(from, to)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	calculated := CalculateAstroMoons(from, to)
+	if len(moons) != len(calculated) {
+		t.Errorf("expected %d moons, but got %d", len(calculated), len(moons))
+	} else {
+		for i, m := range moons {
+			if i < len(table) && !m.Date.Equal(table[i].Date) {
+				t.Errorf("expected the table moon %s, but got %s", table[i].Date, m.Date)
+			}
+			if d := m.Date.Sub(calculated[i].Date); m.Phase != calculated[i].Phase || d > time.Hour || d < -time.Hour {
+				t.Errorf("expected %s %s, but got %s %s", calculated[i].Date, calculated[i].Phase, m.Date, m.Phase)
+			}
+		}
+	}
+
+	_, err = ChainProvider{AerisProvider{Dir: "/nonexistent"}}.AstroMoons(from, to)
+	if err == nil {
+		t.Errorf("expected an error for the missing files")
+	}
+}
+
+func TestAstroMoonLocation(t *testing.T) {
+	var expect, str string
+
+	// The Full Moon of 2016-01-24 01:46 UTC
+	from := time.Date(2016, 1, 20, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2016, 1, 26, 0, 0, 0, 0, time.UTC)
+
+	testLocations := []struct {
+		loc    *time.Location
+		expect string
+	}{
+		{time.UTC, "2016-01-24"},
+		{time.FixedZone("ICT", 7*3600), "2016-01-24"},
+		{time.FixedZone("PST", -8*3600), "2016-01-23"},
+	}
+	for _, test := range testLocations {
+		moons := GetAstroMoonsIn(from, to, test.loc)
+		if len(moons) != 1 {
+			t.Errorf("expected 1 moon, but got %d", len(moons))
+			continue
+		}
+		str = moons[0].Date.Format("2006-01-02")
+		if str != test.expect {
+			t.Errorf("%s: expected %s, but got %s", test.loc, test.expect, str)
+		}
+	}
+
+	// Bangkok, 100.5 E
+	loc := LongitudeLocation(100.5)
+	expect = "LMT+06:42"
+	str = loc.String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+	expect = "LMT-08:00"
+	str = LongitudeLocation(-120).String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// The moon is on the CalDay of its civil date, and the days are in order
+	pst := time.FixedZone("PST", -8*3600)
+	cal_days := GetCalendarCalDaysIn(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC), 0, pst)
+	found := false
+	for i, day := range cal_days {
+		if day.GetAstroMoon().Phase == "full" && dayOf(day.Date).Equal(time.Date(2016, 1, 23, 0, 0, 0, 0, time.UTC)) {
+			found = true
+		}
+		if i > 0 && dayOf(day.Date).Before(dayOf(cal_days[i-1].Date)) {
+			t.Errorf("expected the days in order, but got %s after %s", day.Date, cal_days[i-1].Date)
+		}
+		if i > 0 && dayOf(day.Date).Equal(dayOf(cal_days[i-1].Date)) {
+			t.Errorf("expected one CalDay for %s", day.Date)
+		}
+	}
+	if !found {
+		t.Errorf("expected the Full Moon on the CalDay of 2016-01-23")
+	}
+}
+
+func TestAstronomicalUposathas(t *testing.T) {
+	var expect, str string
+
+	ict := time.FixedZone("ICT", 7*3600)
+
+	mahanikaya := calendarUposathas(2016, CalendarToInt("mahanikaya"), time.UTC)
+	uposathas := AstronomicalUposathas(2016, ict)
+
+	if len(uposathas) != len(mahanikaya) {
+		t.Errorf("expected %d, but got %d", len(mahanikaya), len(uposathas))
+		return
+	}
+
+	// The bookkeeping follows the Mahānikāya calendar
+	for i, u := range uposathas {
+		m := mahanikaya[i]
+		if u.Phase != m.Phase || u.S_Number != m.S_Number || u.S_Total != m.S_Total || u.LunarMonth != m.LunarMonth || u.Event != m.Event {
+			t.Errorf("expected %v, but got %v", m, u)
+		}
+		if u.Calendar != CalendarToInt("astronomical") {
+			t.Errorf("expected the astronomical calendar, but got %d", u.Calendar)
+		}
+		if u.DayOffset != daysBetween(m.Date, u.Date) || (u.DayOffset != 0) != (len(u.Comments) != 0) {
+			t.Errorf("expected the offset and comment of %s, but got %d %s", u.Date, u.DayOffset, u.Comments)
+		}
+	}
+
+	// The Full Moon of 2016-02-22 18:20 UTC is on the next day in Bangkok
+	expect = `2016-02-23 magha 1 Mahānikāya: 2016-02-22
+2016-05-22 vesakha 2 Mahānikāya: 2016-05-20
+2016-07-20 asalha 1 Mahānikāya: 2016-07-19
+2016-10-16 pavarana 0 
+`
+	str = ""
+	for _, u := range uposathas {
+		if len(u.Event) != 0 {
+			str += fmt.Sprintf("%s %s %d %s\n", u.Date.Format("2006-01-02"), u.Event, u.DayOffset, u.Comments)
+		}
+	}
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// The major events are on the astronomical days
+	found := false
+	for _, e := range GenerateCalendarSolarYearIn(2016, CalendarToInt("astronomical"), ict) {
+		if me, ok := e.(MajorEvent); ok && me.Summary == "Māgha Pūjā" {
+			found = me.Date.Format("2006-01-02") == "2016-02-23" && me.Calendar == CalendarToInt("astronomical")
+		}
+	}
+	if !found {
+		t.Errorf("expected Māgha Pūjā on 2016-02-23")
+	}
+}
+
+func TestDeviationReport(t *testing.T) {
+	var expect, str string
+
+	ict := time.FixedZone("ICT", 7*3600)
+	report := GetDeviationReport(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 3, 31, 0, 0, 0, 0, time.UTC), CalendarToInt("mahanikaya"), ict)
+
+	expect = `2016-01-08 new  2016-01-10 08:30 ICT  +44.51 h +2 d
+2016-01-23 full 2016-01-24 08:45 ICT  +20.76 h +1 d
+2016-02-07 new  2016-02-08 21:38 ICT  +33.65 h +1 d
+2016-02-22 full 2016-02-23 01:19 ICT  +13.33 h +1 d magha
+2016-03-07 new  2016-03-09 08:54 ICT  +44.91 h +2 d
+2016-03-22 full 2016-03-23 19:00 ICT  +31.01 h +1 d
+
+Years:
+2016: 6 uposathas, mean +31.36 h, mean abs 31.36 h, max abs 44.91 h, +1 d: 4, +2 d: 2
+
+Centuries:
+2001-2100: 6 uposathas, mean +31.36 h, mean abs 31.36 h, max abs 44.91 h, +1 d: 4, +2 d: 2
+`
+	str = report.String()
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// The astronomical calendar is on the day of the moon
+	report = GetDeviationReport(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2016, 12, 31, 0, 0, 0, 0, time.UTC), CalendarToInt("astronomical"), ict)
+	if len(report.Years) != 1 || report.Years[0].DayCounts[0] != report.Years[0].Count {
+		t.Errorf("expected all on the same day, but got %v", report.Years)
+	}
+
+	// Centuries are counted from the first year
+	report = deviationReport([]Deviation{
+		{Date: time.Date(2000, 6, 1, 0, 0, 0, 0, time.UTC), Hours: 2},
+		{Date: time.Date(2001, 6, 1, 0, 0, 0, 0, time.UTC), Hours: -4},
+	})
+	str = fmt.Sprintf("%s %s", report.Centuries[0].Period, report.Centuries[1].Period)
+	expect = "1901-2000 2001-2100"
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+}
+
+func TestEphemeris(t *testing.T) {
+	// Meeus, Example 25.a, the Sun on 1992 Oct 13.0 TD
+	if l := sunTrueLongitude(2448908.5); math.Abs(l-199.90988) > 0.0001 {
+		t.Errorf("expected 199.90988, but got %.5f", l)
+	}
+
+	// Meeus, Example 47.a, the Moon on 1992 Apr 12.0 TD
+	if l := moonTrueLongitude(2448724.5); math.Abs(l-133.162655) > 0.02 {
+		t.Errorf("expected 133.162655, but got %.5f", l)
+	}
+
+	// Eade's example day, 1963 Jul 5. The Suriyayatra Sun is within a degree
+	// of the sidereal Sun, and the Moon within a few degrees.
+	date := time.Date(1963, 7, 5, 0, 0, 0, 0, time.UTC)
+	rows, err := CompareEphemeris(date, date, 1, EphemerisOptions{})
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	if len(rows) != 1 {
+		t.Errorf("expected 1, but got %d", len(rows))
+		return
+	}
+	r := rows[0]
+	if r.Horakhun != horakhunRef || math.Abs(r.SunError) > 1 || math.Abs(r.MoonError) > 5 {
+		t.Errorf("expected the errors within 1 and 5 degrees, but got %v", r)
+	}
+
+	// The ayanāṃśa only moves the modern positions
+	trop, _ := CompareEphemeris(date, date, 1, EphemerisOptions{Ayanamsa: TropicalAyanamsa})
+	if d := longitudeDifference(trop[0].ModernSun, r.ModernSun); math.Abs(d-r.Ayanamsa) > 0.000001 {
+		t.Errorf("expected %.4f, but got %.4f", r.Ayanamsa, d)
+	}
+
+	if _, err := CompareEphemeris(date, date, 0, EphemerisOptions{}); err == nil {
+		t.Errorf("expected an error for the step")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEphemerisCSV(&buf, rows); err != nil {
+		t.Errorf("%v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expect := "date,horakhun,ayanamsa,suriya_sun,modern_sun,sun_error,suriya_moon,modern_moon,moon_error"
+	if len(lines) != 2 || lines[0] != expect || !strings.HasPrefix(lines[1], "1963-07-06T06:00:00+06:42,484049,") {
+		t.Errorf("expected %s, but got %s", expect, buf.String())
+	}
+
+	// The mean errors of a century. The Suriyayatra year of 292207 / 800 days
+	// is longer than the sidereal year of 365.256363 days, so the Sun falls
+	// behind by 0.2353 degree in a century.
+	meanErrors := func(century int) (float64, float64) {
+		from := time.Date(century, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(century+99, 12, 31, 0, 0, 0, 0, time.UTC)
+		rows, _ := CompareEphemeris(from, to, 10, EphemerisOptions{})
+		var sun, moon float64
+		for _, r := range rows {
+			sun += r.SunError
+			moon += r.MoonError
+		}
+		return sun / float64(len(rows)), moon / float64(len(rows))
+	}
+
+	sun_1300, moon_1300 := meanErrors(1301)
+	sun_1900, moon_1900 := meanErrors(1901)
+
+	drift := (float64(EraDays)/EraYears - 365.256363) * 100 * 360 / 365.256363
+	if d := (sun_1900 - sun_1300) / 6; math.Abs(d+drift) > 0.01 {
+		t.Errorf("expected the Sun to drift %.4f degree per century, but got %.4f", -drift, d)
+	}
+	for _, moon := range []float64{moon_1300, moon_1900} {
+		if math.Abs(moon) > 1.5 {
+			t.Errorf("expected the mean Moon error within 1.5 degrees, but got %.4f", moon)
+		}
+	}
+}
+
+func TestEclipses(t *testing.T) {
+	var expect, str string
+
+	// Meeus, Example 54.a, the partial solar eclipse of 1993 May 21
+	e, ok := modernEclipse(-82, EclipseSolar)
+	expect = "1993-05-21 partial 0.740 1.1348"
+	str = fmt.Sprintf("%s %s %.3f %.4f", e.Date.Format("2006-01-02"), e.Type, e.Magnitude, e.Gamma)
+	if !ok || str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// No eclipse at the Full Moon of 2016 Jan
+	if _, ok := modernEclipse(201, EclipseLunar); ok {
+		t.Errorf("expected no eclipse")
+	}
+
+	// The eclipses of 1963, around Eade's example day
+	from := time.Date(1963, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(1963, 7, 31, 0, 0, 0, 0, time.UTC)
+
+	expect = `1963-07-06 22:02 modern lunar partial 0.705
+1963-07-20 20:35 modern solar total 1.000
+`
+	str = ""
+	for _, e := range ModernEclipses(from, to) {
+		str += fmt.Sprintf("%s %s %s %s %.3f\n", e.Date.Format("2006-01-02 15:04"), e.Method, e.Kind, e.Type, e.Magnitude)
+	}
+	if str != expect {
+		t.Errorf("expected %s, but got %s", expect, str)
+	}
+
+	// The modern eclipses are in the CalDays by default. The lunar eclipse is
+	// on the Āsāḷha Pūjā uposatha
+	found := false
+	for _, day := range GetCalDays(from, to) {
+		for _, e := range day.Eclipses {
+			if e.Method != EclipseModern {
+				t.Errorf("expected only the modern eclipses, but got %s", e.Method)
+			}
+			if e.Kind == EclipseLunar {
+				found = true
+				expect = "Partial Lunar Eclipse on the uposatha"
+				if e.String() != expect || day.GetUposathaMoon().Event != "asalha" {
+					t.Errorf("expected %s, but got %s %s", expect, e, day.GetUposathaMoon().Event)
+				}
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected the lunar eclipse on a CalDay")
+	}
+
+	// No eclipses in the CalDays without a method
+	defer func(methods []string) {
+		CalDayEclipses = methods
+	}(CalDayEclipses)
+	CalDayEclipses = nil
+	for _, day := range GetCalDays(from, to) {
+		if len(day.Eclipses) != 0 {
+			t.Errorf("expected no eclipses, but got %v", day.Eclipses)
+		}
+	}
+}
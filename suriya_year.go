@@ -21,6 +21,22 @@ type SuriyaYear struct {
 }
 
 func (su SuriyaYear) Is_Adhikamasa() bool {
+	if info, ok := su.tableInfo(); ok {
+		return info.IsAdhikamasa
+	}
+	return su.isAdhikamasa()
+}
+
+// The year table values of the year, when su is the year as Init() gives it.
+// A SuriyaYear with other values, such as one built by hand, is not in the
+// table, and the methods calculate from its own fields.
+func (su SuriyaYear) tableInfo() (YearInfo, bool) {
+	info := GetYearInfo(su.Year)
+	return info, info.SuriyaYear == su
+}
+
+// Is_Adhikamasa() without the year table
+func (su SuriyaYear) isAdhikamasa() bool {
 	// If next year also qualifies for adhikamāsa, then this year isn't
 	var su_next SuriyaYear
 	su_next.Init(su.Year + 1)
@@ -34,16 +50,37 @@ func (su SuriyaYear) Would_Be_Adhikamasa() bool {
 }
 
 func (su SuriyaYear) Is_Adhikavara() bool {
+	if info, ok := su.tableInfo(); ok {
+		return info.IsAdhikavara
+	}
+	return su.isAdhikavara()
+}
+
+// Is_Adhikavara() without the year table
+func (su SuriyaYear) isAdhikavara() bool {
 	if UseExceptions {
 		if _, ok := AdhikavaraExceptions[su.Year]; ok {
 			return AdhikavaraExceptions[su.Year]
 		}
 	}
 
-	if su.Is_Adhikamasa() {
+	return su.isRegularAdhikavara()
+}
+
+// Whether the year is adhikavāra by the formulas, without the exceptions.
+func (su SuriyaYear) Is_Regular_Adhikavara() bool {
+	if info, ok := su.tableInfo(); ok {
+		return info.IsRegularAdhikavara
+	}
+	return su.isRegularAdhikavara()
+}
+
+// Is_Regular_Adhikavara() without the year table
+func (su SuriyaYear) isRegularAdhikavara() bool {
+	if su.isAdhikamasa() {
 		return false
 	}
-	if su.Has_Carried_Adhikavara() {
+	if su.hasCarriedAdhikavara() {
 		return true
 	} else {
 		return su.Would_Be_Adhikavara()
@@ -55,7 +92,22 @@ func (su SuriyaYear) String() string {
 	return n
 }
 
+// A SuriyaYear of a year from MinYear to MaxYear, or a YearRangeError.
+func NewSuriyaYear(ce_year int) (SuriyaYear, error) {
+	var su SuriyaYear
+	if ce_year < MinYear || ce_year > MaxYear {
+		return su, YearRangeError{Year: ce_year}
+	}
+	su.Init(ce_year)
+	return su, nil
+}
+
 func (su *SuriyaYear) Init(ce_year int) {
+	su.init(ce_year, nil)
+}
+
+// Init, recording the steps in ex when not nil.
+func (su *SuriyaYear) init(ce_year int, ex *Explanation) {
 	su.Year = ce_year
 	su.BE_Year = su.Year + BEdiff
 	su.CS_Year = su.Year - CSdiff
@@ -69,23 +121,30 @@ func (su *SuriyaYear) Init(ce_year int) {
 	// +1 is another constant correction, H3
 	a = (su.CS_Year * EraDays) + EraHorakhun
 	su.Horakhun = int(math.Floor(float64(a/KammacubalaDaily + 1)))
+	ex.add("A", "CS year * 292207 + 373", a)
+	ex.add("A", "Horakhun", su.Horakhun)
 	// Horakhun = 483969
 
 	su.Kammacubala = KammacubalaDaily - a%KammacubalaDaily
+	ex.add("A", "Kammacubala", su.Kammacubala)
 	// Kammacubala = 552
 
 	su.Uccabala = (su.Horakhun + EraUccabala) % 3232
+	ex.add("A", "Uccabala", su.Uccabala)
 	// Uccabala = 1780
 
 	a = (su.Horakhun * CycleDaily) + EraAvoman
 	su.Avoman = a % CycleSolar
+	ex.add("A", "Avoman", su.Avoman)
 	// Avoman = 61
 
 	b = int(math.Floor(float64(a) / CycleSolar))
 	su.Masaken = int(math.Floor(float64((b + EraMasaken + su.Horakhun) / MonthLength)))
+	ex.add("A", "Masaken", su.Masaken)
 	// Masaken = 16388
 
 	su.Tithi = (b + su.Horakhun) % MonthLength
+	ex.add("A", "Tithi", su.Tithi)
 	// Tithi = 23
 }
 
@@ -99,9 +158,11 @@ Uccabala: %d
 Avoman: %d
 Masaken: %d
 Tithi: %d
+Animal: %s
+Decade: %s
 `
 
-	return fmt.Sprintf(fmtStr, su.Year, su.BE_Year, su.CS_Year, su.Horakhun, su.Kammacubala, su.Uccabala, su.Avoman, su.Masaken, su.Tithi)
+	return fmt.Sprintf(fmtStr, su.Year, su.BE_Year, su.CS_Year, su.Horakhun, su.Kammacubala, su.Uccabala, su.Avoman, su.Masaken, su.Tithi, su.AnimalName(), su.DecadeName())
 }
 
 func (su SuriyaYear) Is_Suriya_Leap() bool {
@@ -127,9 +188,17 @@ func (su SuriyaYear) Would_Be_Adhikavara() bool {
 }
 
 func (su SuriyaYear) Has_Carried_Adhikavara() bool {
+	if info, ok := su.tableInfo(); ok {
+		return info.HasCarriedAdhikavara
+	}
+	return su.hasCarriedAdhikavara()
+}
+
+// Has_Carried_Adhikavara() without the year table
+func (su SuriyaYear) hasCarriedAdhikavara() bool {
 	last_year := SuriyaYear{}
 	last_year.Init(su.Year - 1)
-	return last_year.Is_Adhikamasa() && last_year.Would_Be_Adhikavara()
+	return last_year.isAdhikamasa() && last_year.Would_Be_Adhikavara()
 }
 
 // Determine the position in the 57 year cycle. Assume 1984 = 1, 2040 = 57, 2041 = 1.
@@ -176,12 +245,20 @@ func (su SuriyaYear) DeltaAdhikavara() int {
 
 // Length of the lunar year in days
 func (su SuriyaYear) YearLength() int {
+	if info, ok := su.tableInfo(); ok {
+		return info.YearLength
+	}
+	return yearLength(su.isAdhikamasa(), su.isAdhikavara())
+}
+
+// YearLength() without the year table
+func yearLength(is_adhikamasa bool, is_adhikavara bool) int {
 	// In a common year, there are six alternating 29 and 30 day lunar months.
 	days := 6 * (30 + 29)
-	if su.Is_Adhikamasa() {
+	if is_adhikamasa {
 		// In an adhikamāsa year, there is an extra 30 day month.
 		days = days + 30
-	} else if su.Is_Adhikavara() {
+	} else if is_adhikavara {
 		// In an adhikavāra year, there is an extra day.
 		days = days + 1
 	}
@@ -190,17 +267,24 @@ func (su SuriyaYear) YearLength() int {
 
 // Date of Asalha Puja
 func (su SuriyaYear) AsalhaPuja() time.Time {
+	if info, ok := su.tableInfo(); ok {
+		return info.AsalhaPuja
+	}
+	return asalhaPuja(CalculatePreviousKattika(su.Year), su.isAdhikamasa(), su.isAdhikavara())
+}
+
+// AsalhaPuja() without the year table
+func asalhaPuja(prev_kattika time.Time, is_adhikamasa bool, is_adhikavara bool) time.Time {
 	// In a common year, Asalha Puja is the last day of the 8th month.
 	days := 4 * (29 + 30)
-	if su.Is_Adhikamasa() {
+	if is_adhikamasa {
 		// In an adhikamāsa year, the extra month (2nd Asalha) is a 30 day month.
 		days = days + 30
-	} else if su.Is_Adhikavara() {
+	} else if is_adhikavara {
 		// In an adhikavāra year, the 8th month (Asalha) is 30 days instead of 29 days.
 		days = days + 1
 	}
 
-	prev_kattika := CalculatePreviousKattika(su.Year)
 	date := prev_kattika.Add(time.Duration(days) * time.Hour * 24)
 	return date
 }
@@ -9,7 +9,7 @@ import (
 
 type UposathaMoon struct {
 	Date          time.Time
-	Calendar      int    // 0 mahanikaya, 1 dhammayut, 2 srilanka, 3 myanmar
+	Calendar      int    // 0 mahanikaya, 1 dhammayut, 2 srilanka, 3 myanmar, 4 khmer, 5 astronomical
 	Status        int    // 0 draft, 1 predicted, 2 confirmed
 	Phase         string // only new or full. waxing and waning will be derived.
 	Event         string // magha, vesakha, asalha, pavarana
@@ -21,20 +21,25 @@ type UposathaMoon struct {
 	LunarSeason   int    // 1-3, an int code to an []string array of names
 	LunarYear     int
 	HasAdhikavara bool
+	Name          string `json:",omitempty"` // name of the day, such as the Sri Lankan Poya
+	YearAnimal    string `json:",omitempty"` // animal name of the year, see YearNameChangeover
+	YearDecade    string `json:",omitempty"` // decade name of the year
+	DayOffset     int    `json:",omitempty"` // days from the Mahānikāya uposatha, in the astronomical calendar
 	Source        string
 	Comments      string
 }
 
 func (last_uposatha UposathaMoon) NextUposatha() UposathaMoon {
+	year := GetYearInfo(last_uposatha.Date.Year())
 
-	lu := last_uposatha
-	var nu UposathaMoon // next uposatha
+	return last_uposatha.nextUposatha(year.IsAdhikamasa, year.IsAdhikavara)
+}
 
-	var su_year SuriyaYear
-	su_year.Init(lu.Date.Year())
+// The next uposatha in a year with the given extra month or extra day.
+func (last_uposatha UposathaMoon) nextUposatha(is_adhikamasa_year bool, is_adhikavara_year bool) UposathaMoon {
 
-	is_adhikamasa_year := su_year.Is_Adhikamasa()
-	is_adhikavara_year := su_year.Is_Adhikavara()
+	lu := last_uposatha
+	var nu UposathaMoon // next uposatha
 
 	nu.Status = 0   // predicted
 	nu.Calendar = 0 // mahanikaya
@@ -173,6 +178,9 @@ func (m UposathaMoon) GetDate() time.Time {
 }
 
 func (m UposathaMoon) String() string {
+	if len(m.Name) != 0 {
+		return fmt.Sprintf("%s - %d day %s %d/%d", m.Name, m.U_Days, SeasonName(m.LunarSeason), m.S_Number, m.S_Total)
+	}
 	if len(m.Phase) != 0 {
 		return fmt.Sprintf("%s Moon - %d day %s %d/%d", s.Title(m.Phase), m.U_Days, SeasonName(m.LunarSeason), m.S_Number, m.S_Total)
 	}
@@ -0,0 +1,107 @@
+package suriya
+
+import (
+	"time"
+)
+
+/*
+The twelve-year animal cycle and the decade (sok) of the CS year, as Thai
+almanacs label the years, such as "Pi Mamae, Sapphasok" for CS 1377.
+
+The decade is the last digit of the CS year, the animal is (CS + 10) % 12,
+from Chuat.
+
+The label changes with the year in one of the historical conventions:
+
+- on Wan Thaloeng Sok, when the CS year changes
+- on the first day of lunar month 5, Citta
+- on the first day of lunar month 1, Māgasira
+*/
+
+const (
+	ChangeoverSongkran = 0
+	ChangeoverMonth5   = 1
+	ChangeoverMonth1   = 2
+)
+
+var animalName = map[int]string{
+	0:  "Chuat",  // rat
+	1:  "Chalu",  // ox
+	2:  "Khan",   // tiger
+	3:  "Tho",    // rabbit
+	4:  "Marong", // naga
+	5:  "Maseng", // small snake
+	6:  "Mamia",  // horse
+	7:  "Mamae",  // goat
+	8:  "Wok",    // monkey
+	9:  "Raka",   // rooster
+	10: "Cho",    // dog
+	11: "Kun",    // pig
+}
+
+var decadeName = map[int]string{
+	0: "Samrittisok",
+	1: "Ekkasok",
+	2: "Thosok",
+	3: "Trisok",
+	4: "Chattawasok",
+	5: "Benchasok",
+	6: "Chasok",
+	7: "Sapphasok",
+	8: "Atthasok",
+	9: "Noppasok",
+}
+
+func AnimalName(number int) string {
+	return animalName[number]
+}
+
+func DecadeName(number int) string {
+	return decadeName[number]
+}
+
+// 0-11, 0 is Chuat
+func (su SuriyaYear) Animal() int {
+	return ((su.CS_Year+10)%12 + 12) % 12
+}
+
+// 0-9, the last digit of the CS year
+func (su SuriyaYear) Decade() int {
+	return (su.CS_Year%10 + 10) % 10
+}
+
+func (su SuriyaYear) AnimalName() string {
+	return AnimalName(su.Animal())
+}
+
+func (su SuriyaYear) DecadeName() string {
+	return DecadeName(su.Decade())
+}
+
+// The SuriyaYear which labels the date, in a changeover convention.
+func DateToNamedYear(date time.Time, changeover int) SuriyaYear {
+	return lunarDateToNamedYear(DateToLunarDate(date), changeover)
+}
+
+func lunarDateToNamedYear(ld LunarDate, changeover int) SuriyaYear {
+	var ce_year int
+
+	// The lunar year (BE) begins with month 1, and its New Year is in the
+	// following April.
+	switch changeover {
+	case ChangeoverMonth1:
+		ce_year = ld.BE_Year - BEdiff
+	case ChangeoverMonth5:
+		if ld.LunarMonth >= 1 && ld.LunarMonth <= 4 {
+			ce_year = ld.BE_Year - BEdiff - 1
+		} else {
+			ce_year = ld.BE_Year - BEdiff
+		}
+	default:
+		ce_year = ld.CS_Year + CSdiff
+	}
+
+	var su SuriyaYear
+	su.Init(ce_year)
+	return su
+}
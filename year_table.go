@@ -0,0 +1,119 @@
+package suriya
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+A table of the year values which are expensive to calculate, such as whether
+the year is adhikamāsa, which needs the next year, or adhikavāra, which needs
+the last year.
+
+The years are calculated when first asked for, or with Precompute() for a
+range. The table is safe to use from several goroutines.
+
+The values are of the years as SuriyaYear.Init() gives them, the SuriyaYear
+methods of a year with other values calculate from its own fields. The values
+depend on UseExceptions, which is part of the key. Call Reset()
+after changing AdhikavaraExceptions. Both are read without synchronization, as
+everywhere in the package, so set them before the table is used and don't
+change them while other goroutines may be calling it.
+*/
+
+type YearInfo struct {
+	Year                 int // Common Era
+	SuriyaYear           SuriyaYear
+	IsAdhikamasa         bool
+	IsAdhikavara         bool
+	IsRegularAdhikavara  bool // by the formulas, without the exceptions
+	HasCarriedAdhikavara bool
+	YearLength           int       // days of the lunar year
+	Kattika              time.Time // the Kattika Full Moon before the year
+	AsalhaPuja           time.Time
+}
+
+type yearTableKey struct {
+	year          int
+	useExceptions bool
+}
+
+type YearTable struct {
+	mu    sync.RWMutex
+	years map[yearTableKey]YearInfo
+}
+
+func NewYearTable() *YearTable {
+	return &YearTable{years: make(map[yearTableKey]YearInfo)}
+}
+
+// The table used by the SuriyaYear methods and CalculatePreviousKattika()
+var DefaultYearTable = NewYearTable()
+
+func GetYearInfo(ce_year int) YearInfo {
+	return DefaultYearTable.Get(ce_year)
+}
+
+// The values of the year, calculated if not in the table yet
+func (t *YearTable) Get(ce_year int) YearInfo {
+	key := yearTableKey{year: ce_year, useExceptions: UseExceptions}
+
+	t.mu.RLock()
+	info, ok := t.years[key]
+	t.mu.RUnlock()
+	if ok {
+		return info
+	}
+
+	info = calculateYearInfo(ce_year)
+
+	t.mu.Lock()
+	t.years[key] = info
+	t.mu.Unlock()
+
+	return info
+}
+
+// Calculate the years from from_year to to_year, inclusive
+func (t *YearTable) Precompute(from_year int, to_year int) {
+	for year := from_year; year <= to_year; year++ {
+		t.Get(year)
+	}
+}
+
+// Empty the table
+func (t *YearTable) Reset() {
+	t.mu.Lock()
+	t.years = make(map[yearTableKey]YearInfo)
+	t.mu.Unlock()
+}
+
+// "adhikamasa", "adhikavara" or "common"
+func (info YearInfo) Kind() string {
+	if info.IsAdhikamasa {
+		return "adhikamasa"
+	} else if info.IsAdhikavara {
+		return "adhikavara"
+	}
+	return "common"
+}
+
+func calculateYearInfo(ce_year int) YearInfo {
+	var su SuriyaYear
+	su.Init(ce_year)
+
+	info := YearInfo{
+		Year:                 ce_year,
+		SuriyaYear:           su,
+		IsAdhikamasa:         su.isAdhikamasa(),
+		IsAdhikavara:         su.isAdhikavara(),
+		IsRegularAdhikavara:  su.isRegularAdhikavara(),
+		HasCarriedAdhikavara: su.hasCarriedAdhikavara(),
+		Kattika:              calculatePreviousKattika(ce_year),
+	}
+
+	info.YearLength = yearLength(info.IsAdhikamasa, info.IsAdhikavara)
+	info.AsalhaPuja = asalhaPuja(info.Kattika, info.IsAdhikamasa, info.IsAdhikavara)
+
+	return info
+}